@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// AdminConfig configures the admin server added by Admin.
+type AdminConfig struct {
+	// Addr is the TCP address to listen on, e.g. "127.0.0.1:9090".
+	Addr string
+	// Token authenticates POST /restart and /stop as a bearer token. Both
+	// endpoints are disabled when it is empty.
+	Token string
+}
+
+// Admin returns an fx option serving the app's operational endpoints on a
+// separate listener obtained from Listeners, so it survives restarts like
+// any other socket:
+//
+//	GET  /healthz   liveness report
+//	GET  /readyz    readiness report
+//	GET  /status    name, version, state, uptime and restart count
+//	GET  /config    effective config, redacted (?format=yaml for YAML)
+//	POST /restart   App.Restart, detached from the request
+//	POST /stop      App.Stop, detached from the request
+//
+// /status and /config are served for apps with the Status and PrintConfig
+// methods of BaseApp.
+//
+// Add it from a boot hook: a.OnBoot().BindFunc(app.Options(app.Admin(cfg))).
+func Admin(cfg AdminConfig) fx.Option {
+	return fx.Module("app.admin", fx.Invoke(func(lc fx.Lifecycle, a App, health *Health, listeners *Listeners) {
+		srv := &http.Server{
+			Handler:           adminHandler(a, health, cfg.Token),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				ln, err := listeners.Listen("tcp", cfg.Addr)
+				if err != nil {
+					return err
+				}
+				go func() { _ = srv.Serve(ln) }()
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				return srv.Shutdown(ctx)
+			},
+		})
+	}))
+}
+
+func adminHandler(a App, health *Health, token string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, health.Liveness(r.Context()))
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, health.Readiness(r.Context()))
+	})
+	if s, ok := a.(StatusReporter); ok {
+		mux.HandleFunc("GET /status", func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, http.StatusOK, s.Status())
+		})
+	}
+	if p, ok := a.(ConfigPrinter); ok {
+		mux.HandleFunc("GET /config", func(w http.ResponseWriter, r *http.Request) {
+			format := FormatJSON
+			if r.URL.Query().Get("format") == string(FormatYAML) {
+				format = FormatYAML
+			}
+			w.Header().Set("Content-Type", "application/"+string(format))
+			if err := p.PrintConfig(w, format); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		})
+	}
+
+	// The lifecycle calls outlive the request: shutting down waits for
+	// in-flight requests, including this one.
+	lifecycle := func(call func(context.Context) error) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !authorized(r, token) {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			ctx := context.WithoutCancel(r.Context())
+			go func() { _ = call(ctx) }()
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}
+	mux.HandleFunc("POST /restart", lifecycle(a.Restart))
+	mux.HandleFunc("POST /stop", lifecycle(a.Stop))
+
+	return mux
+}
+
+func authorized(r *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+func writeHealth(w http.ResponseWriter, report HealthReport) {
+	code := http.StatusOK
+	if report.Status == StatusDown {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, report)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,131 @@
+package app_test
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rumorsflow/app"
+)
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() = %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return addr
+}
+
+func adminRequest(t *testing.T, method, url, token string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest() = %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s = %v", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestAdmin(t *testing.T) {
+	addr := freeAddr(t)
+	base := "http://" + addr
+
+	a := configApp(app.Config{
+		StartTimeout: 10 * time.Second,
+		StopTimeout:  10 * time.Second,
+		Name:         "test-app",
+		Version:      "0.0.1",
+		ConfigRaw:    []byte(`{"db":{"user":"admin","password":"hunter2"}}`),
+	})
+	a.OnBoot().BindFunc(app.LoadConfig[secretConfig]())
+	a.OnBoot().BindFunc(app.Options(app.Admin(app.AdminConfig{Addr: addr, Token: "s3cret"})))
+
+	runErr, started := startRun(t, a)
+	waitClosed(t, started, "app did not start")
+
+	t.Run("status", func(t *testing.T) {
+		code, body := adminRequest(t, http.MethodGet, base+"/status", "")
+		if code != http.StatusOK {
+			t.Fatalf("GET /status = %d", code)
+		}
+		var status app.Status
+		if err := json.Unmarshal([]byte(body), &status); err != nil {
+			t.Fatalf("decode /status: %v", err)
+		}
+		if status.Name != "test-app" || status.Version != "0.0.1" || status.State != "running" {
+			t.Errorf("GET /status = %+v", status)
+		}
+	})
+
+	t.Run("health", func(t *testing.T) {
+		for _, path := range []string{"/healthz", "/readyz"} {
+			if code, body := adminRequest(t, http.MethodGet, base+path, ""); code != http.StatusOK {
+				t.Errorf("GET %s = %d %s, want 200", path, code, body)
+			}
+		}
+	})
+
+	t.Run("config", func(t *testing.T) {
+		code, body := adminRequest(t, http.MethodGet, base+"/config", "")
+		if code != http.StatusOK || !strings.Contains(body, `"user": "admin"`) {
+			t.Errorf("GET /config = %d %s, want config", code, body)
+		}
+		if strings.Contains(body, "hunter2") {
+			t.Errorf("GET /config = %s, leaks secret", body)
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		for _, token := range []string{"", "wrong"} {
+			if code, _ := adminRequest(t, http.MethodPost, base+"/stop", token); code != http.StatusUnauthorized {
+				t.Errorf("POST /stop with token %q = %d, want 401", token, code)
+			}
+		}
+		if code, _ := adminRequest(t, http.MethodGet, base+"/stop", "s3cret"); code != http.StatusMethodNotAllowed {
+			t.Errorf("GET /stop = %d, want 405", code)
+		}
+		if a.State() != app.StateRunning {
+			t.Errorf("State() = %v after rejected requests, want running", a.State())
+		}
+	})
+
+	if code, _ := adminRequest(t, http.MethodPost, base+"/stop", "s3cret"); code != http.StatusAccepted {
+		t.Fatalf("POST /stop = %d, want 202", code)
+	}
+	if err := waitErr(t, runErr); err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if _, err := net.DialTimeout("tcp", addr, time.Second); err == nil {
+		t.Error("admin server still listening after stop")
+	}
+}
+
+func TestAdminWithoutToken(t *testing.T) {
+	addr := freeAddr(t)
+	a := newStartedApp(t, app.Admin(app.AdminConfig{Addr: addr}))
+
+	if code, _ := adminRequest(t, http.MethodPost, "http://"+addr+"/restart", ""); code != http.StatusUnauthorized {
+		t.Errorf("POST /restart = %d, want 401", code)
+	}
+	if a.State() != app.StateRunning {
+		t.Errorf("State() = %v, want running", a.State())
+	}
+}
@@ -6,7 +6,13 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"reflect"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
@@ -40,12 +46,38 @@ type BootEvent struct {
 	Ctx     context.Context
 	Logger  fxevent.Logger
 	Options []fx.Option
+
+	tracker *bootTracker
+	timer   *handlerTimer
+}
+
+// Next calls the next OnBoot handler.
+func (e *BootEvent) Next() error {
+	if e.tracker != nil {
+		e.tracker.next(e.Options)
+	}
+	return e.timer.next(e.Event.Next)
+}
+
+func (e *BootEvent) setTimer(t *handlerTimer) {
+	e.timer = t
 }
 
 type StartEvent struct {
 	hook.Event
 	App App
 	Ctx context.Context
+
+	timer *handlerTimer
+}
+
+// Next calls the next OnStart handler.
+func (e *StartEvent) Next() error {
+	return e.timer.next(e.Event.Next)
+}
+
+func (e *StartEvent) setTimer(t *handlerTimer) {
+	e.timer = t
 }
 
 type StopEvent struct {
@@ -53,6 +85,17 @@ type StopEvent struct {
 	App       App
 	Ctx       context.Context
 	IsRestart bool
+
+	timer *handlerTimer
+}
+
+// Next calls the next OnStop handler.
+func (e *StopEvent) Next() error {
+	return e.timer.next(e.Event.Next)
+}
+
+func (e *StopEvent) setTimer(t *handlerTimer) {
+	e.timer = t
 }
 
 type App interface {
@@ -72,34 +115,92 @@ type App interface {
 }
 
 type Config struct {
-	StartTimeout    time.Duration
-	StopTimeout     time.Duration
-	ConfigUnmarshal func(ctx context.Context, data []byte, out any) error
-	ConfigRaw       []byte
-	ConfigFiles     []string
-	Name            string
-	Version         string
-	EnvPrefix       string
-	EnvOptions      *env.Options
+	StartTimeout      time.Duration
+	StopTimeout       time.Duration
+	ConfigUnmarshal   func(ctx context.Context, data []byte, out any) error
+	ConfigDecoders    map[string]ConfigDecoder
+	ConfigRaw         []byte
+	ConfigFiles       []string
+	Name              string
+	Version           string
+	EnvPrefix         string
+	EnvOptions        *env.Options
+	Signals           map[os.Signal]SignalAction
+	SlowHookThreshold float64
+	StopPhases        []StopPhase
+	DrainDelay        time.Duration
+	Clock             Clock
+	// DotenvFiles are loaded into the process environment by LoadConfig,
+	// later files overriding earlier ones and variables already set
+	// overriding them all unless DotenvOverride is set. Missing files are
+	// skipped; DotenvRequired makes the first one, the base file, required.
+	// See DotenvCascade.
+	DotenvFiles    []string
+	DotenvOverride bool
+	DotenvRequired bool
+	// DotenvScoped keeps the dotenv values out of the process environment,
+	// so child processes and a restarted process do not inherit them; they
+	// only reach LoadConfig, through EnvOptions.Environment.
+	DotenvScoped bool
+	// SecretResolvers resolve references like file:///run/secrets/db in
+	// config fields marked secret. See SecretResolver.
+	SecretResolvers map[string]SecretResolver
 }
 
 type BaseApp struct {
-	startTimeout    time.Duration
-	stopTimeout     time.Duration
-	name            string
-	version         string
-	configFiles     []string
-	configRaw       []byte
-	configUnmarshal func(ctx context.Context, data []byte, out any) error
-	fxApp           atomic.Pointer[fx.App]
-	fxLogger        fxevent.Logger
-	envOptions      env.Options
-	onBootstrap     *hook.Hook[*BootEvent]
-	onStart         *hook.Hook[*StartEvent]
-	onStop          *hook.Hook[*StopEvent]
-	stopping        atomic.Bool
-	stopErr         error
-	done            chan struct{}
+	startTimeout      time.Duration
+	stopTimeout       time.Duration
+	name              string
+	version           string
+	configFiles       []string
+	loadedConfigFiles []string
+	configRaw         []byte
+	configUnmarshal   func(ctx context.Context, data []byte, out any) error
+	configDecoders    map[string]ConfigDecoder
+	secretResolvers   map[string]SecretResolver
+	fxApp             atomic.Pointer[fx.App]
+	fxLogger          fxevent.Logger
+	envOptions        env.Options
+	dotenvFiles       []string
+	dotenvOverride    bool
+	dotenvRequired    bool
+	dotenvScoped      bool
+	dotenvMu          sync.Mutex
+	dotenvSet         map[string]string
+	listeners         *Listeners
+	health            *Health
+	shutdown          *Shutdown
+	workers           *Workers
+	scheduler         *Scheduler
+	notifier          *notifier
+	hooks             hookTracker
+	timingsMu         sync.Mutex
+	timings           []HookTiming
+	slowHookThreshold float64
+	onSlowHook        *hook.Hook[*SlowHookEvent]
+	restarts          int
+	startedAt         time.Time
+	onBootstrap       *hook.Hook[*BootEvent]
+	onStart           *hook.Hook[*StartEvent]
+	onStop            *hook.Hook[*StopEvent]
+	onReload          *hook.Hook[*ReloadEvent]
+	onSignal          *hook.Hook[*SignalEvent]
+	signals           map[os.Signal]SignalAction
+	shutdownRequested atomic.Bool
+	reloadMu          sync.Mutex
+	configMu          sync.RWMutex
+	configs           []*configEntry
+	provenanceMu      sync.RWMutex
+	provenance        map[reflect.Type]Provenance
+	onStateChange     *hook.Hook[*StateChangeEvent]
+	stateMu           sync.Mutex
+	state             State
+	waiters           map[int]chan struct{}
+	stopping          atomic.Bool
+	tornDown          atomic.Bool
+	stopErr           error
+	failErr           error
+	done              chan struct{}
 }
 
 func Options(options ...fx.Option) func(*BootEvent) error {
@@ -118,6 +219,19 @@ func LoadConfig[C any]() func(*BootEvent) error {
 
 		event.Options = append(event.Options, fx.Supply(cfg))
 
+		if r, ok := event.App.(configRegistrar); ok {
+			app := event.App
+			r.registerConfig(&configEntry{
+				typ:   reflect.TypeFor[C](),
+				value: cfg,
+				load: func(ctx context.Context) (any, error) {
+					var cfg C
+					err := app.LoadConfig(ctx, &cfg)
+					return cfg, err
+				},
+			})
+		}
+
 		return event.Next()
 	}
 }
@@ -131,20 +245,52 @@ func NewBaseApp(cfg Config) *BaseApp {
 		envOptions.Prefix = cfg.EnvPrefix
 	}
 
-	return &BaseApp{
-		startTimeout:    cfg.StartTimeout,
-		stopTimeout:     cfg.StopTimeout,
-		name:            cfg.Name,
-		version:         cfg.Version,
-		configFiles:     cfg.ConfigFiles,
-		configRaw:       cfg.ConfigRaw,
-		configUnmarshal: cfg.ConfigUnmarshal,
-		envOptions:      envOptions,
-		onBootstrap:     &hook.Hook[*BootEvent]{},
-		onStart:         &hook.Hook[*StartEvent]{},
-		onStop:          &hook.Hook[*StopEvent]{},
-		done:            make(chan struct{}),
+	app := &BaseApp{
+		startTimeout:      cfg.StartTimeout,
+		stopTimeout:       cfg.StopTimeout,
+		name:              cfg.Name,
+		version:           cfg.Version,
+		configFiles:       cfg.ConfigFiles,
+		configRaw:         cfg.ConfigRaw,
+		configUnmarshal:   cfg.ConfigUnmarshal,
+		envOptions:        envOptions,
+		dotenvFiles:       cfg.DotenvFiles,
+		dotenvOverride:    cfg.DotenvOverride,
+		dotenvRequired:    cfg.DotenvRequired,
+		dotenvScoped:      cfg.DotenvScoped,
+		listeners:         newListeners(),
+		onBootstrap:       &hook.Hook[*BootEvent]{},
+		onStart:           &hook.Hook[*StartEvent]{},
+		onStop:            &hook.Hook[*StopEvent]{},
+		onReload:          &hook.Hook[*ReloadEvent]{},
+		onSignal:          &hook.Hook[*SignalEvent]{},
+		onSlowHook:        &hook.Hook[*SlowHookEvent]{},
+		slowHookThreshold: cfg.SlowHookThreshold,
+		signals:           newSignals(cfg.Signals),
+		onStateChange:     &hook.Hook[*StateChangeEvent]{},
+		waiters:           make(map[int]chan struct{}),
+		done:              make(chan struct{}),
+	}
+	app.configDecoders = app.newConfigDecoders(cfg.ConfigDecoders)
+	app.secretResolvers = app.newSecretResolvers(cfg.SecretResolvers)
+	app.health = newHealth(app.State)
+	app.notifier = newNotifier()
+	app.shutdown = newShutdown(cfg.StopPhases, cfg.DrainDelay)
+	app.workers = newWorkers(app.fail)
+	// Custom phases may leave out StopWorkers; stop then stops them after
+	// the last phase.
+	_ = app.shutdown.Append(StopWorkers, app.workers.stop)
+	app.scheduler = newScheduler(app.workers, cfg.Clock, func() fxevent.Logger { return app.fxLogger })
+	if app.slowHookThreshold <= 0 {
+		app.slowHookThreshold = defaultSlowHookThreshold
+	}
+
+	if restarts, ok := os.LookupEnv(envRestarts); ok {
+		app.restarts, _ = strconv.Atoi(restarts)
+		_ = os.Unsetenv(envRestarts)
 	}
+
+	return app
 }
 
 func (app *BaseApp) Name() string {
@@ -175,43 +321,88 @@ func (app *BaseApp) OnStop() *hook.Hook[*StopEvent] {
 	return app.onStop
 }
 
+// LoadConfig fills each of outs from ConfigRaw, decoded by ConfigUnmarshal,
+// then from every ConfigFiles file in order, then from the environment, and
+// finally applies defaults and validation for outs that implement them.
+// Files are decoded by their extension with ConfigDecoders, falling back to
+// ConfigUnmarshal for other extensions. Files are read and decoded even when
+// ConfigUnmarshal is nil; a file without a decoder fails LoadConfig.
 func (app *BaseApp) LoadConfig(ctx context.Context, outs ...any) error {
-	var files [][]byte
-	if app.configUnmarshal != nil {
-		files = make([][]byte, len(app.configFiles))
-		for i, file := range app.configFiles {
-			data, err := os.ReadFile(file)
-			if err != nil {
-				return fmt.Errorf("failed to read config file %s: %w", file, err)
+	type configFile struct {
+		name    string
+		data    []byte
+		decoder ConfigDecoder
+	}
+
+	envOptions, err := app.loadDotenv()
+	if err != nil {
+		return err
+	}
+	ctx = withEnvOptions(ctx, envOptions)
+
+	names, err := app.resolveConfigFiles()
+	if err != nil {
+		return err
+	}
+
+	files := make([]configFile, len(names))
+	for i, file := range names {
+		decoder, ok := app.configDecoders[strings.ToLower(filepath.Ext(file))]
+		if !ok {
+			if decoder = app.configUnmarshal; decoder == nil {
+				return fmt.Errorf("app: no config decoder for file %s", file)
 			}
-			files[i] = data
 		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read config file %s: %w", file, err)
+		}
+		dotenv := strings.EqualFold(filepath.Ext(file), ".env")
+		if data, err = interpolate(file, data, lookupEnv(envOptions), dotenv); err != nil {
+			return err
+		}
+		files[i] = configFile{name: file, data: data, decoder: decoder}
+	}
+
+	raw, err := interpolate("ConfigRaw", app.configRaw, lookupEnv(envOptions), false)
+	if err != nil {
+		return err
 	}
 
 	for _, out := range outs {
-		if app.configUnmarshal != nil {
-			if len(app.configRaw) > 0 {
-				if err := app.configUnmarshal(ctx, app.configRaw, out); err != nil {
-					return err
-				}
+		trace := newConfigTrace(out, envOptions)
+
+		if app.configUnmarshal != nil && len(raw) > 0 {
+			if err := app.configUnmarshal(ctx, raw, out); err != nil {
+				return err
 			}
+			trace.raw()
+		}
 
-			for _, file := range files {
-				if err := app.configUnmarshal(ctx, file, out); err != nil {
-					return err
-				}
+		for _, file := range files {
+			if err := file.decoder(ctx, file.data, out); err != nil {
+				return fmt.Errorf("failed to decode config file %s: %w", file.name, err)
 			}
+			trace.file(file.name)
 		}
 
-		if err := env.ParseWithOptions(out, app.envOptions); err != nil {
+		if err := env.ParseWithOptions(out, envOptions); err != nil {
 			return err
 		}
+		trace.env()
+
+		refs, err := app.resolveSecrets(ctx, out)
+		if err != nil {
+			return err
+		}
+		trace.secrets(refs)
 
 		if c, ok := out.(defaulter); ok {
 			c.SetDefaults()
+			trace.defaults()
 		}
 
-		var err error
 		switch v := out.(type) {
 		case validatableWithContext:
 			err = v.ValidateWithContext(ctx)
@@ -221,25 +412,64 @@ func (app *BaseApp) LoadConfig(ctx context.Context, outs ...any) error {
 			err = v.Validate()
 		}
 		if err != nil {
-			return fmt.Errorf("failed to validate config: %w", err)
+			return wrapError(ErrConfigValidation, err)
 		}
+
+		app.setProvenance(out, trace.result())
 	}
+
+	app.configMu.Lock()
+	app.loadedConfigFiles = names
+	app.configMu.Unlock()
+
 	return nil
 }
 
 func (app *BaseApp) Boot(ctx context.Context) error {
+	if err := app.transition("boot", StateBooting, StateNew); err != nil {
+		return err
+	}
+
+	// systemd's watchdog covers the whole process life, boot included.
+	app.notifier.startWatchdog(app.done)
+
 	event := &BootEvent{App: app, Ctx: ctx, Logger: fxevent.NopLogger}
 
-	return app.OnBoot().Trigger(event, app.createFxApp)
+	leave := app.hooks.enter("OnBoot handlers")
+	err := trigger(app, PhaseBoot, app.OnBoot(), event, app.createFxApp)
+	leave()
+	if err != nil {
+		_ = app.transition("boot", StateNew, StateBooting)
+		return markError(ErrBoot, err)
+	}
+
+	return app.transition("boot", StateBooted, StateBooting)
 }
 
 func (app *BaseApp) Start(ctx context.Context) error {
+	if err := app.transition("start", StateStarting, StateBooted); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, app.startTimeout)
 	defer cancel()
 
 	event := &StartEvent{App: app, Ctx: ctx}
 
-	return app.OnStart().Trigger(event, app.start)
+	leave := app.hooks.enter("OnStart handlers")
+	err := trigger(app, PhaseStart, app.OnStart(), event, app.start)
+	leave()
+	if err != nil {
+		_ = app.transition("start", StateBooted, StateStarting)
+		return timeoutError(ctx, ErrStartTimeout, err)
+	}
+
+	// A shutdown that began while starting keeps precedence.
+	if app.transition("start", StateRunning, StateStarting) == nil {
+		app.notifier.ready(app.restarts > 0)
+	}
+
+	return nil
 }
 
 func (app *BaseApp) Stop(ctx context.Context) error {
@@ -248,18 +478,35 @@ func (app *BaseApp) Stop(ctx context.Context) error {
 		return app.stopErr
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, app.stopTimeout)
+	if err := app.transition("stop", StateStopping, StateBooted, StateStarting, StateRunning); err != nil {
+		return app.finish(err)
+	}
+
+	app.notifier.stopping()
+
+	// Readiness is down from here on; the drain delay comes on top of the
+	// stop budget.
+	ctx, cancel := context.WithTimeout(ctx, app.shutdown.DrainDelay()+app.stopTimeout)
 	defer cancel()
 
 	event := &StopEvent{App: app, Ctx: ctx}
 
-	return app.finish(app.OnStop().Trigger(event, app.stop))
+	defer app.hooks.enter("OnStop handlers")()
+
+	err := trigger(app, PhaseStop, app.OnStop(), event, app.stop)
+	if errors.As(err, new(*PanicError)) {
+		// A handler panicked, possibly before the fx app was stopped.
+		err = errors.Join(err, app.teardown(ctx, false))
+	}
+
+	return app.finish(timeoutError(ctx, ErrStopTimeout, err))
 }
 
 // Restart stops the application and replaces the current process with a new
 // instance of the same binary via exec, keeping the same PID. On success it
 // never returns; stop errors are ignored so a failed graceful shutdown does
-// not prevent the exec.
+// not prevent the exec. A panic while stopping makes it clean up and exit
+// the process instead, with the ExitCode of the error.
 //
 // When calling this from code managed by the application itself (an HTTP
 // handler, a worker), detach it — otherwise graceful shutdown waits for the
@@ -268,7 +515,7 @@ func (app *BaseApp) Stop(ctx context.Context) error {
 //	go func() { _ = app.Restart(context.WithoutCancel(ctx)) }()
 func (app *BaseApp) Restart(ctx context.Context) error {
 	if runtime.GOOS == "windows" {
-		return errors.New("app: restart is not supported on windows")
+		return fmt.Errorf("%w on windows", ErrRestartUnsupported)
 	}
 
 	if !app.stopping.CompareAndSwap(false, true) {
@@ -276,21 +523,47 @@ func (app *BaseApp) Restart(ctx context.Context) error {
 		return app.stopErr
 	}
 
+	if err := app.transition("restart", StateRestarting, StateBooted, StateStarting, StateRunning); err != nil {
+		return app.finish(err)
+	}
+
+	// To systemd an exec restart is a reload: the PID stays and the new
+	// image reports READY=1 once it runs.
+	app.notifier.reloading()
+
 	// Restart is a point of no return: keep the caller's values but drop its
 	// cancellation so a dying request cannot cut the shutdown short.
-	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), app.stopTimeout)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), app.shutdown.DrainDelay()+app.stopTimeout)
 	defer cancel()
 
 	event := &StopEvent{App: app, Ctx: ctx, IsRestart: true}
 
-	return app.finish(app.OnStop().Trigger(event, app.stop, app.restart))
+	defer app.hooks.enter("OnStop handlers")()
+
+	err := trigger(app, PhaseStop, app.OnStop(), event, app.stop, app.restart)
+	if errors.As(err, new(*PanicError)) {
+		// Exec-ing a process that panicked on its way down is no safer than
+		// a fresh start: clean up and exit, leaving the restart to the
+		// supervisor.
+		err = app.finish(timeoutError(ctx, ErrStopTimeout, errors.Join(err, app.teardown(ctx, false))))
+		fmt.Fprintf(os.Stderr, "%s: restart failed, exiting: %v\n", app.name, err)
+		os.Exit(ExitCode(err))
+	}
+
+	return app.finish(timeoutError(ctx, ErrStopTimeout, err))
 }
 
 // finish records the shutdown outcome and releases everyone blocked on it:
 // concurrent Stop/Restart callers and Run's select.
 func (app *BaseApp) finish(err error) error {
+	app.stateMu.Lock()
+	err = errors.Join(app.failErr, err)
+	app.stateMu.Unlock()
+
 	app.stopErr = err
+	_ = app.transition("stop", StateStopped)
 	close(app.done)
+
 	return err
 }
 
@@ -305,7 +578,7 @@ func (app *BaseApp) Run(ctx context.Context) error {
 
 	fxApp := app.fxApp.Load()
 	if fxApp == nil {
-		return errors.New("app: not booted")
+		return ErrNotBooted
 	}
 
 	if runtime.GOOS == "windows" {
@@ -319,24 +592,42 @@ func (app *BaseApp) Run(ctx context.Context) error {
 		}
 	}
 
-	restartSignal := make(chan os.Signal, 1)
-	signal.Notify(restartSignal, syscall.SIGUSR1)
+	signals := make(chan os.Signal, 1)
+	for sig := range app.signals {
+		signal.Notify(signals, sig)
+	}
 
-	defer signal.Stop(restartSignal)
+	defer signal.Stop(signals)
 
-	select {
-	case sig := <-fxApp.Wait():
-		app.logSignal(sig.Signal)
+	if restartsOn(app.signals, syscall.SIGUSR1) {
+		restartListeners.Add(1)
+		defer restartListeners.Add(-1)
+	}
 
-		return app.Stop(ctx)
-	case sig := <-restartSignal:
-		app.logSignal(sig)
+	wait := fxApp.Wait()
+	for {
+		select {
+		case sig := <-wait:
+			// A mapped SIGINT or SIGTERM arrives on signals as well, but
+			// fx.Shutdowner requests, which fx reports as SIGTERM, do not.
+			if _, ok := app.signals[sig.Signal]; ok && !app.shutdownRequested.CompareAndSwap(true, false) {
+				continue
+			}
+			app.logSignal(sig.Signal)
 
-		return app.Restart(ctx)
-	case <-app.done:
-		// Stop or Restart was invoked directly by application code; the
-		// process was not replaced, so surface the outcome and exit.
-		return app.stopErr
+			// Keep serving signals while stopping, so a hung shutdown can
+			// still be dumped.
+			go func() { _ = app.Stop(ctx) }()
+		case sig := <-signals:
+			// Errors reach the OnSignal handlers; Stop and Restart outcomes
+			// surface through done below.
+			go func() { _ = app.handleSignal(ctx, sig) }()
+		case <-app.done:
+			// Stop or Restart was invoked by a signal action or directly by
+			// application code; the process was not replaced, so surface the
+			// outcome and exit.
+			return app.stopErr
+		}
 	}
 }
 
@@ -347,29 +638,51 @@ func (app *BaseApp) logSignal(sig os.Signal) {
 func (app *BaseApp) start(event *StartEvent) error {
 	fxApp := app.fxApp.Load()
 	if fxApp == nil {
-		return errors.New("app: not booted")
+		return ErrNotBooted
 	}
 
 	if err := fxApp.Start(event.Ctx); err != nil {
 		return fmt.Errorf("app: unable to start: %w", err)
 	}
 
+	app.listeners.release()
+	app.workers.start()
+
 	return event.Next()
 }
 
 func (app *BaseApp) stop(event *StopEvent) error {
+	if err := app.teardown(event.Ctx, event.IsRestart); err != nil {
+		return err
+	}
+
+	return event.Next()
+}
+
+// teardown runs the shutdown phases, which stop the fx app, and makes sure
+// the workers stopped. Only the first call does so, letting Stop finish the job after a handler
+// panicked either before or after it.
+func (app *BaseApp) teardown(ctx context.Context, isRestart bool) error {
+	if !app.tornDown.CompareAndSwap(false, true) {
+		return nil
+	}
+
 	fxApp := app.fxApp.Load()
 	if fxApp == nil {
-		return errors.New("app: not booted")
+		return ErrNotBooted
 	}
 
-	if err := fxApp.Stop(event.Ctx); err != nil {
-		if !event.IsRestart {
-			return err
-		}
+	err := errors.Join(
+		app.shutdown.run(ctx, app, app.stopTimeout, fxApp.Stop),
+		app.workers.stop(ctx),
+	)
+	if !isRestart {
+		// Keep the sockets open across a restart so the new process can
+		// adopt them; a plain stop frees the ports.
+		err = errors.Join(err, app.listeners.close())
 	}
 
-	return event.Next()
+	return err
 }
 
 func (app *BaseApp) restart(event *StopEvent) error {
@@ -386,7 +699,20 @@ func (app *BaseApp) restart(event *StopEvent) error {
 		}
 	}
 
-	return syscall.Exec(execPath, os.Args, os.Environ())
+	manifest, err := app.listeners.manifest()
+	if err != nil {
+		return err
+	}
+
+	environ := slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		return strings.HasPrefix(kv, envListeners+"=") || strings.HasPrefix(kv, envRestarts+"=")
+	})
+	if manifest != "" {
+		environ = append(environ, envListeners+"="+manifest)
+	}
+	environ = append(environ, envRestarts+"="+strconv.Itoa(app.restarts+1))
+
+	return syscall.Exec(execPath, os.Args, environ)
 }
 
 func (app *BaseApp) createFxApp(event *BootEvent) error {
@@ -396,13 +722,80 @@ func (app *BaseApp) createFxApp(event *BootEvent) error {
 
 	app.fxLogger = event.Logger
 
-	app.fxApp.Store(fx.New(
+	fxApp, err := app.newFxApp(event, app.registries(), func() fxevent.Logger {
+		return &hookLogger{logger: app.fxLogger, app: app}
+	}, fx.Decorate(app.recoverLifecycle, app.recordShutdowner))
+	if err != nil {
+		return err
+	}
+
+	app.fxApp.Store(fxApp)
+
+	return event.Next()
+}
+
+// registries are the app's services supplied to the fx graph, which the
+// graph's invokes register their parts with.
+type registries struct {
+	listeners *Listeners
+	health    *Health
+	shutdown  *Shutdown
+	workers   *Workers
+	scheduler *Scheduler
+}
+
+func (app *BaseApp) registries() registries {
+	return registries{
+		listeners: app.listeners,
+		health:    app.health,
+		shutdown:  app.shutdown,
+		workers:   app.workers,
+		scheduler: app.scheduler,
+	}
+}
+
+// dryRunRegistries returns throwaway registries for a container that is
+// built but never started, so health checks, workers, jobs, stop hooks and
+// sockets registered by its invokes do not stay behind for Boot. They are
+// released with close.
+func (app *BaseApp) dryRunRegistries() registries {
+	workers := newWorkers(func(error) {})
+	shutdown := newShutdown(app.shutdown.phases, app.shutdown.drain)
+	_ = shutdown.Append(StopWorkers, workers.stop)
+
+	return registries{
+		listeners: emptyListeners(),
+		health:    newHealth(app.State),
+		shutdown:  shutdown,
+		workers:   workers,
+		scheduler: newScheduler(workers, app.scheduler.clock, func() fxevent.Logger { return nil }),
+	}
+}
+
+func (r registries) close() error {
+	return errors.Join(r.workers.stop(context.Background()), r.listeners.close())
+}
+
+// newFxApp builds the fx container from the boot options and reports graph
+// and invoke errors instead of deferring them to Start.
+func (app *BaseApp) newFxApp(event *BootEvent, reg registries, logger func() fxevent.Logger, extra ...fx.Option) (*fx.App, error) {
+	fxApp := fx.New(
+		fx.RecoverFromPanics(),
 		fx.StartTimeout(app.startTimeout),
 		fx.StopTimeout(app.stopTimeout),
-		fx.WithLogger(func() fxevent.Logger { return app.fxLogger }),
+		fx.WithLogger(logger),
 		fx.Supply(fx.Annotate(app, fx.As(new(App)))),
+		fx.Supply(reg.listeners),
+		fx.Supply(reg.health),
+		fx.Supply(reg.shutdown),
+		fx.Supply(reg.workers),
+		fx.Supply(reg.scheduler),
 		fx.Options(event.Options...),
-	))
+		fx.Options(extra...),
+	)
+	if err := fxApp.Err(); err != nil {
+		return nil, newGraphError(err)
+	}
 
-	return event.Next()
+	return fxApp, nil
 }
@@ -0,0 +1,232 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"runtime/debug"
+	"strings"
+)
+
+// exitUsage follows the flag package rather than sysexits' EX_USAGE.
+const exitUsage = 2
+
+type configFilesSetter interface {
+	setConfigFiles(files []string)
+}
+
+type mainOptions struct {
+	configs []any
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+type MainOption func(*mainOptions)
+
+// WithConfigs lists the config types checked by the "config" subcommands,
+// e.g. WithConfigs(new(HTTPConfig), new(DBConfig)). Only the types matter;
+// fresh values are loaded on every call.
+func WithConfigs(cfgs ...any) MainOption {
+	return func(o *mainOptions) {
+		o.configs = append(o.configs, cfgs...)
+	}
+}
+
+// WithOutput redirects what the subcommands print, os.Stdout and os.Stderr
+// by default.
+func WithOutput(stdout, stderr io.Writer) MainOption {
+	return func(o *mainOptions) {
+		o.stdout = stdout
+		o.stderr = stderr
+	}
+}
+
+// stringsFlag collects every occurrence of a repeatable flag.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// Main runs the command line interface built around a with os.Args and
+// exits the process with the resulting code. See Execute.
+func Main(a App, opts ...MainOption) {
+	os.Exit(Execute(context.Background(), a, os.Args[1:], opts...))
+}
+
+// Execute runs a single command line invocation and returns the process
+// exit code:
+//
+//	[--config FILE]... [run]               boot, start and wait for shutdown
+//	version                                print name, version and build info
+//	config print [--format json|yaml]      print the loaded configs, redacted
+//	config validate                        load and validate the configs
+//	check                                  verify the dependency graph
+//	graph [--format dot|json]              print the dependency graph
+//
+// Every --config flag replaces Config.ConfigFiles when given.
+func Execute(ctx context.Context, a App, args []string, opts ...MainOption) int {
+	o := mainOptions{stdout: os.Stdout, stderr: os.Stderr}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var configFiles stringsFlag
+	newFlagSet := func(name string) *flag.FlagSet {
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		fs.SetOutput(o.stderr)
+		fs.Var(&configFiles, "config", "config `file`, repeatable")
+		return fs
+	}
+
+	fs := newFlagSet(a.Name())
+	if err := fs.Parse(args); err != nil {
+		return usageCode(err)
+	}
+
+	command, args := "run", fs.Args()
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	if command == "config" {
+		if len(args) == 0 {
+			fmt.Fprintln(o.stderr, "app: config requires a subcommand: print or validate")
+			return exitUsage
+		}
+		command, args = "config "+args[0], args[1:]
+	}
+
+	fs = newFlagSet(command)
+	var format string
+	switch command {
+	case "config print":
+		fs.StringVar(&format, "format", string(FormatJSON), "output `format`: json or yaml")
+	case "graph":
+		fs.StringVar(&format, "format", "dot", "output `format`: dot or json")
+	}
+	if err := fs.Parse(args); err != nil {
+		return usageCode(err)
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(o.stderr, "app: unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return exitUsage
+	}
+
+	if len(configFiles) > 0 {
+		if s, ok := a.(configFilesSetter); ok {
+			s.setConfigFiles(configFiles)
+		}
+	}
+
+	var err error
+	switch command {
+	case "run":
+		err = a.Run(ctx)
+	case "version":
+		err = printVersion(o.stdout, a)
+	case "config print":
+		var cfgs []any
+		if cfgs, err = loadConfigs(ctx, a, o.configs); err == nil {
+			err = writeConfig(o.stdout, ConfigFormat(format), explainOf(a), cfgs...)
+		}
+	case "config validate":
+		_, err = loadConfigs(ctx, a, o.configs)
+	case "check":
+		v, ok := a.(Verifier)
+		if !ok {
+			err = fmt.Errorf("app: %T cannot verify its dependency graph", a)
+			break
+		}
+		err = v.Verify(ctx)
+	case "graph":
+		gr, ok := a.(Grapher)
+		if !ok {
+			err = fmt.Errorf("app: %T cannot describe its dependency graph", a)
+			break
+		}
+		var g *Graph
+		if g, err = gr.Graph(ctx); err == nil {
+			switch format {
+			case "dot":
+				err = g.WriteDOT(o.stdout)
+			case "json":
+				err = g.WriteJSON(o.stdout)
+			default:
+				err = fmt.Errorf("app: unsupported graph format %q", format)
+			}
+		}
+	default:
+		fmt.Fprintf(o.stderr, "app: unknown command %q\n", command)
+		return exitUsage
+	}
+
+	if err != nil {
+		fmt.Fprintf(o.stderr, "%s: %v\n", a.Name(), err)
+		return ExitCode(err)
+	}
+	return ExitOK
+}
+
+func usageCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	return exitUsage
+}
+
+// loadConfigs loads a fresh value of every config type in cfgs.
+func loadConfigs(ctx context.Context, a App, cfgs []any) ([]any, error) {
+	if len(cfgs) == 0 {
+		return nil, errors.New("app: no config types, pass app.WithConfigs to Main")
+	}
+
+	outs := make([]any, len(cfgs))
+	for i, cfg := range cfgs {
+		t := reflect.TypeOf(cfg)
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		outs[i] = reflect.New(t).Interface()
+	}
+
+	if err := a.LoadConfig(ctx, outs...); err != nil {
+		return nil, err
+	}
+	return outs, nil
+}
+
+func printVersion(w io.Writer, a App) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", a.Name(), a.Version())
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(&b, "  go:      %s\n", info.GoVersion)
+		fmt.Fprintf(&b, "  module:  %s %s\n", info.Main.Path, info.Main.Version)
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				fmt.Fprintf(&b, "  commit:  %s\n", s.Value)
+			case "vcs.time":
+				fmt.Fprintf(&b, "  built:   %s\n", s.Value)
+			case "vcs.modified":
+				fmt.Fprintf(&b, "  dirty:   %s\n", s.Value)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (app *BaseApp) setConfigFiles(files []string) {
+	app.configFiles = files
+}
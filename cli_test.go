@@ -0,0 +1,136 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/rumorsflow/app"
+)
+
+func execute(t *testing.T, a app.App, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := app.Execute(context.Background(), a, args,
+		app.WithConfigs(new(netConfig), validatedConfig{}),
+		app.WithOutput(&stdout, &stderr),
+	)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestExecuteVersion(t *testing.T) {
+	code, out, _ := execute(t, newApp(t), "version")
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	if !strings.HasPrefix(out, "test-app 0.0.1\n") {
+		t.Errorf("version output = %q, want name and version first", out)
+	}
+}
+
+func TestExecuteConfig(t *testing.T) {
+	good := writeConfigFile(t, "config.json", `{"addr":"file","port":9}`)
+	bad := writeConfigFile(t, "bad.json", `{"fail":true}`)
+
+	t.Run("print", func(t *testing.T) {
+		code, out, stderr := execute(t, configApp(app.Config{}), "--config", good, "config", "print", "--format", "yaml")
+		if code != 0 {
+			t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+		}
+		if !strings.Contains(out, "addr: file") {
+			t.Errorf("config print output = %q, want addr from --config file", out)
+		}
+	})
+
+	t.Run("validate ok", func(t *testing.T) {
+		if code, _, stderr := execute(t, configApp(app.Config{}), "config", "validate", "--config", good); code != 0 {
+			t.Errorf("exit code = %d, stderr = %s", code, stderr)
+		}
+	})
+
+	t.Run("validate failure", func(t *testing.T) {
+		code, _, stderr := execute(t, configApp(app.Config{}), "--config", bad, "config", "validate")
+		if code == 0 {
+			t.Error("exit code = 0, want failure")
+		}
+		if !strings.Contains(stderr, errSentinel.Error()) {
+			t.Errorf("stderr = %q, want validation error", stderr)
+		}
+	})
+
+	t.Run("missing subcommand", func(t *testing.T) {
+		if code, _, _ := execute(t, configApp(app.Config{}), "config"); code != 2 {
+			t.Errorf("exit code = %d, want 2", code)
+		}
+	})
+}
+
+func TestExecuteCheck(t *testing.T) {
+	var started bool
+	a := newApp(t, fx.Invoke(func(lc fx.Lifecycle) {
+		lc.Append(fx.StartHook(func() { started = true }))
+	}))
+
+	if code, _, stderr := execute(t, a, "check"); code != 0 {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	if started {
+		t.Error("check started the app")
+	}
+}
+
+// plainApp has only the methods of the App interface, like an App
+// implemented outside the package.
+type plainApp struct{ app.App }
+
+func TestExecuteWithPlainApp(t *testing.T) {
+	a := plainApp{newApp(t)}
+
+	if code, _, stderr := execute(t, a, "config", "print"); code != 0 {
+		t.Errorf("config print: exit code = %d, stderr = %s", code, stderr)
+	}
+	for _, command := range []string{"check", "graph"} {
+		if code, _, stderr := execute(t, a, command); code == 0 || !strings.Contains(stderr, "plainApp") {
+			t.Errorf("%s: exit code = %d, stderr = %s, want an error naming the app", command, code, stderr)
+		}
+	}
+}
+
+func TestExecuteRun(t *testing.T) {
+	a := newApp(t)
+	a.OnStart().BindFunc(func(e *app.StartEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+		go func() { _ = a.Stop(context.Background()) }()
+		return nil
+	})
+
+	done := make(chan int, 1)
+	go func() {
+		code, _, _ := execute(t, a)
+		done <- code
+	}()
+
+	select {
+	case code := <-done:
+		if code != 0 {
+			t.Errorf("exit code = %d, want 0", code)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("run did not return")
+	}
+}
+
+func TestExecuteUsage(t *testing.T) {
+	for _, args := range [][]string{{"bogus"}, {"--bogus"}, {"version", "extra"}} {
+		if code, _, _ := execute(t, newApp(t), args...); code != 2 {
+			t.Errorf("Execute(%v) exit code = %d, want 2", args, code)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// optionalPrefix marks a ConfigFiles entry that may match nothing.
+const optionalPrefix = "optional:"
+
+// Optional marks a Config.ConfigFiles entry as optional: LoadConfig skips it
+// when the file or directory does not exist or the pattern matches nothing,
+// as for local override files.
+//
+//	ConfigFiles: []string{"config.yaml", "conf.d", app.Optional("config.local.yaml")}
+func Optional(entry string) string {
+	return optionalPrefix + entry
+}
+
+// resolveConfigFiles expands the ConfigFiles entries into the files to load,
+// in order. An entry is a file, a directory whose config files load in
+// lexical order like conf.d, or a glob pattern of filepath.Match whose
+// matches load in lexical order. An entry that exists as written is never a
+// pattern, so a path like "conf [old].yaml" needs no escaping. Within
+// directories only non-hidden files with a known decoder extension are
+// picked up.
+func (app *BaseApp) resolveConfigFiles() ([]string, error) {
+	var files []string
+	for _, entry := range app.configFiles {
+		pattern, optional := strings.CutPrefix(entry, optionalPrefix)
+
+		matches, err := app.expandConfigEntry(pattern)
+		if err != nil {
+			if optional && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func (app *BaseApp) expandConfigEntry(pattern string) ([]string, error) {
+	if !hasGlobMeta(pattern) {
+		return app.expandConfigPath(pattern)
+	}
+	if _, err := os.Stat(pattern); err == nil {
+		return app.expandConfigPath(pattern)
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("app: invalid config file pattern %s: %w", pattern, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("app: no config files match %s: %w", pattern, fs.ErrNotExist)
+	}
+	slices.Sort(matches)
+
+	var files []string
+	for _, match := range matches {
+		expanded, err := app.expandConfigPath(match)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, expanded...)
+	}
+	return files, nil
+}
+
+func (app *BaseApp) expandConfigPath(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config directory %s: %w", path, err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if _, ok := app.configDecoders[strings.ToLower(filepath.Ext(name))]; ok {
+			files = append(files, filepath.Join(path, name))
+		}
+	}
+	return files, nil
+}
+
+// hasGlobMeta reports whether path has the characters that start a
+// wildcard. A backslash alone only escapes them, and is a path separator on
+// Windows.
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// ConfigFiles returns the config files the last LoadConfig read, in the
+// order they were applied. Entries of Config.ConfigFiles may name a file, a
+// directory or a glob pattern, optionally marked with Optional; this is the
+// list they expanded to, e.g. for logging at boot.
+func (app *BaseApp) ConfigFiles() []string {
+	app.configMu.RLock()
+	defer app.configMu.RUnlock()
+
+	return slices.Clone(app.loadedConfigFiles)
+}
@@ -0,0 +1,108 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/rumorsflow/app"
+)
+
+func writeConfigTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		writeConfigFile(t, filepath.Join(dir, name), content)
+	}
+	return dir
+}
+
+func TestLoadConfigDirectory(t *testing.T) {
+	dir := writeConfigTree(t, map[string]string{
+		"conf.d/20-port.yaml":  "port: 2",
+		"conf.d/10-base.json":  `{"addr":"base","port":1}`,
+		"conf.d/.hidden.json":  `{"addr":"hidden"}`,
+		"conf.d/README.md":     "# not config",
+		"conf.d/sub/30-x.json": `{"addr":"nested"}`,
+	})
+
+	a := configApp(app.Config{ConfigFiles: []string{filepath.Join(dir, "conf.d")}})
+
+	var cfg netConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if cfg.Addr != "base" || cfg.Port != 2 {
+		t.Errorf("LoadConfig() = %+v, want files applied in lexical order", cfg)
+	}
+
+	want := []string{filepath.Join(dir, "conf.d/10-base.json"), filepath.Join(dir, "conf.d/20-port.yaml")}
+	if got := a.ConfigFiles(); !slices.Equal(got, want) {
+		t.Errorf("ConfigFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadConfigGlob(t *testing.T) {
+	dir := writeConfigTree(t, map[string]string{
+		"b.json": `{"port":2}`,
+		"a.json": `{"addr":"a","port":1}`,
+		"c.yaml": "addr: c",
+	})
+
+	a := configApp(app.Config{ConfigFiles: []string{filepath.Join(dir, "*.json")}})
+
+	var cfg netConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if cfg.Addr != "a" || cfg.Port != 2 {
+		t.Errorf("LoadConfig() = %+v, want matches applied in lexical order", cfg)
+	}
+}
+
+func TestLoadConfigOptionalFiles(t *testing.T) {
+	dir := writeConfigTree(t, map[string]string{"config.json": `{"addr":"base"}`})
+
+	a := configApp(app.Config{ConfigFiles: []string{
+		filepath.Join(dir, "config.json"),
+		app.Optional(filepath.Join(dir, "config.local.json")),
+		app.Optional(filepath.Join(dir, "conf.d")),
+		app.Optional(filepath.Join(dir, "*.local.yaml")),
+	}})
+
+	var cfg netConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if got, want := a.ConfigFiles(), []string{filepath.Join(dir, "config.json")}; !slices.Equal(got, want) {
+		t.Errorf("ConfigFiles() = %v, want %v", got, want)
+	}
+
+	for _, entry := range []string{"config.local.json", "*.local.yaml"} {
+		a = configApp(app.Config{ConfigFiles: []string{filepath.Join(dir, entry)}})
+		if err := a.LoadConfig(context.Background(), &cfg); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("LoadConfig(%s) = %v, want %v", entry, err, fs.ErrNotExist)
+		}
+	}
+}
+
+func TestLoadConfigLiteralPathWithGlobMeta(t *testing.T) {
+	dir := writeConfigTree(t, map[string]string{
+		"conf [old].json": `{"addr":"old"}`,
+		"conf o.json":     `{"addr":"glob"}`,
+	})
+
+	a := configApp(app.Config{ConfigFiles: []string{filepath.Join(dir, "conf [old].json")}})
+
+	var cfg netConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if cfg.Addr != "old" {
+		t.Errorf("LoadConfig() = %+v, want the file named as written", cfg)
+	}
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/caarlos0/env/v11"
+	"github.com/joho/godotenv"
+	"go.yaml.in/yaml/v3"
+	"gopkg.in/ini.v1"
+)
+
+// ConfigDecoder decodes a config payload into out, merging onto the values
+// already present.
+//
+// LoadConfig picks the decoder for each of Config.ConfigFiles by extension:
+// ".json", ".yaml", ".yml", ".toml", ".ini" and ".env" are built in and
+// Config.ConfigDecoders adds or replaces entries. Files with an unknown
+// extension, and ConfigRaw, fall back to Config.ConfigUnmarshal. Payloads
+// have ${VAR}, ${VAR:-default} and ${VAR:?message} expanded before they are
+// decoded.
+type ConfigDecoder = func(ctx context.Context, data []byte, out any) error
+
+func jsonDecoder(_ context.Context, data []byte, out any) error {
+	return json.Unmarshal(data, out)
+}
+
+func yamlDecoder(_ context.Context, data []byte, out any) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return yaml.Unmarshal(data, out)
+}
+
+func tomlDecoder(_ context.Context, data []byte, out any) error {
+	return toml.Unmarshal(data, out)
+}
+
+func iniDecoder(_ context.Context, data []byte, out any) error {
+	f, err := ini.Load(data)
+	if err != nil {
+		return err
+	}
+	return f.MapTo(out)
+}
+
+// dotenvDecoder treats a .env file as an environment overlay and maps it
+// through the same env tags and options LoadConfig uses for the process
+// environment. The real environment stays underneath so required fields are
+// not reported missing merely because the file omits them.
+func (app *BaseApp) dotenvDecoder(ctx context.Context, data []byte, out any) error {
+	values, err := godotenv.UnmarshalBytes(data)
+	if err != nil {
+		return err
+	}
+
+	opts := app.envOptionsFrom(ctx)
+	if opts.Environment != nil {
+		opts.Environment = maps.Clone(opts.Environment)
+	} else {
+		opts.Environment = env.ToMap(os.Environ())
+	}
+	maps.Copy(opts.Environment, values)
+
+	return env.ParseWithOptions(out, opts)
+}
+
+// newConfigDecoders returns the built-in decoders keyed by lowercase file
+// extension, overridden and extended by custom. A decoder set to nil in
+// custom disables the extension, deferring it to ConfigUnmarshal.
+func (app *BaseApp) newConfigDecoders(custom map[string]ConfigDecoder) map[string]ConfigDecoder {
+	decoders := map[string]ConfigDecoder{
+		".json": jsonDecoder,
+		".yaml": yamlDecoder,
+		".yml":  yamlDecoder,
+		".toml": tomlDecoder,
+		".ini":  iniDecoder,
+		".env":  app.dotenvDecoder,
+	}
+	for ext, decoder := range custom {
+		ext = strings.ToLower(ext)
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if decoder == nil {
+			delete(decoders, ext)
+			continue
+		}
+		decoders[ext] = decoder
+	}
+	return decoders
+}
@@ -0,0 +1,129 @@
+package app_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rumorsflow/app"
+)
+
+type formatConfig struct {
+	Addr string `json:"addr" yaml:"addr" toml:"addr" ini:"addr" env:"ADDR"`
+	Port int    `json:"port" yaml:"port" toml:"port" ini:"port" env:"PORT"`
+}
+
+func TestLoadConfigDecodersByExtension(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"json", "c.json", `{"addr":"x","port":7}`},
+		{"yaml", "c.yaml", "addr: x\nport: 7\n"},
+		{"yml", "c.YML", "addr: x\nport: 7\n"},
+		{"toml", "c.toml", "addr = \"x\"\nport = 7\n"},
+		{"ini", "c.ini", "addr = x\nport = 7\n"},
+		{"env", "c.env", "TESTAPP_ADDR=x\nTESTAPP_PORT=7\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := app.NewBaseApp(app.Config{
+				ConfigFiles: []string{writeConfigFile(t, tt.file, tt.content)},
+				EnvPrefix:   "TESTAPP_",
+			})
+
+			var cfg formatConfig
+			if err := a.LoadConfig(ctx, &cfg); err != nil {
+				t.Fatalf("LoadConfig() = %v", err)
+			}
+			if cfg.Addr != "x" || cfg.Port != 7 {
+				t.Errorf("cfg = %+v, want Addr=x Port=7", cfg)
+			}
+		})
+	}
+}
+
+func TestLoadConfigMixedFormats(t *testing.T) {
+	a := app.NewBaseApp(app.Config{
+		ConfigFiles: []string{
+			writeConfigFile(t, "base.yaml", "addr: base\nport: 1\n"),
+			writeConfigFile(t, "override.json", `{"port":2}`),
+		},
+	})
+
+	var cfg formatConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if cfg.Addr != "base" || cfg.Port != 2 {
+		t.Errorf("cfg = %+v, want Addr=base Port=2", cfg)
+	}
+}
+
+// Files used to be skipped without ConfigUnmarshal; they are decoded by
+// extension now, while ConfigRaw still needs ConfigUnmarshal.
+func TestLoadConfigWithoutConfigUnmarshal(t *testing.T) {
+	a := app.NewBaseApp(app.Config{
+		ConfigRaw:   []byte(`{"addr":"raw","port":1}`),
+		ConfigFiles: []string{writeConfigFile(t, "config.yaml", "port: 2\n")},
+	})
+
+	var cfg formatConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if cfg.Addr != "" || cfg.Port != 2 {
+		t.Errorf("cfg = %+v, want the file decoded and ConfigRaw ignored", cfg)
+	}
+}
+
+func TestLoadConfigDecoderFallback(t *testing.T) {
+	ctx := context.Background()
+	path := writeConfigFile(t, "config.conf", `{"addr":"x"}`)
+
+	t.Run("no decoder", func(t *testing.T) {
+		a := app.NewBaseApp(app.Config{ConfigFiles: []string{path}})
+
+		var cfg formatConfig
+		err := a.LoadConfig(ctx, &cfg)
+		if err == nil || !strings.Contains(err.Error(), path) {
+			t.Errorf("LoadConfig() = %v, want error naming %s", err, path)
+		}
+	})
+
+	t.Run("config unmarshal", func(t *testing.T) {
+		a := configApp(app.Config{ConfigFiles: []string{path}})
+
+		var cfg formatConfig
+		if err := a.LoadConfig(ctx, &cfg); err != nil {
+			t.Fatalf("LoadConfig() = %v", err)
+		}
+		if cfg.Addr != "x" {
+			t.Errorf("cfg.Addr = %q, want %q", cfg.Addr, "x")
+		}
+	})
+
+	t.Run("custom decoder", func(t *testing.T) {
+		var called bool
+		a := app.NewBaseApp(app.Config{
+			ConfigFiles: []string{path},
+			ConfigDecoders: map[string]app.ConfigDecoder{
+				"conf": func(context.Context, []byte, any) error {
+					called = true
+					return nil
+				},
+			},
+		})
+
+		var cfg formatConfig
+		if err := a.LoadConfig(ctx, &cfg); err != nil {
+			t.Fatalf("LoadConfig() = %v", err)
+		}
+		if !called {
+			t.Error("custom decoder was not called")
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/fx/fxevent"
+)
+
+// runningHook is a lifecycle hook that has been entered but not left yet.
+type runningHook struct {
+	name  string
+	since time.Time
+	// fx hooks also keep what a HookTiming needs.
+	phase    string
+	function string
+	caller   string
+}
+
+// hookTracker records which lifecycle hooks are executing, so a dump taken
+// while a start or shutdown hangs can name the culprit.
+type hookTracker struct {
+	mu      sync.Mutex
+	running []runningHook
+}
+
+// enter marks name as executing until the returned func is called.
+func (t *hookTracker) enter(name string) func() {
+	t.add(runningHook{name: name})
+	return func() { t.leave(name) }
+}
+
+func (t *hookTracker) add(h runningHook) {
+	h.since = time.Now()
+
+	t.mu.Lock()
+	t.running = append(t.running, h)
+	t.mu.Unlock()
+}
+
+func (t *hookTracker) leave(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := len(t.running) - 1; i >= 0; i-- {
+		if t.running[i].name == name {
+			t.running = slices.Delete(t.running, i, i+1)
+			return
+		}
+	}
+}
+
+func (t *hookTracker) snapshot() []runningHook {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.running)
+}
+
+// Dump writes a diagnostic report to w: the lifecycle state, the hooks
+// currently executing, the stacks of all goroutines and a heap profile in
+// pprof's text format.
+func (app *BaseApp) Dump(w io.Writer) error {
+	now := time.Now()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "app: %s %s\n", app.name, app.version)
+	fmt.Fprintf(&b, "time: %s\n", now.Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "state: %s\n", app.State())
+	b.WriteString("executing:\n")
+	running := app.hooks.snapshot()
+	if len(running) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, h := range running {
+		fmt.Fprintf(&b, "  %s for %s\n", h.name, now.Sub(h.since).Round(time.Millisecond))
+	}
+	b.WriteString("\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return err
+	}
+	if err := pprof.Lookup("goroutine").WriteTo(w, 2); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+	return pprof.Lookup("heap").WriteTo(w, 1)
+}
+
+// dumpTo writes a Dump to a new timestamped file in dir, or without a dir
+// to the output of a console fx logger and stderr otherwise.
+func (app *BaseApp) dumpTo(dir string) error {
+	if dir == "" {
+		w := io.Writer(os.Stderr)
+		if l, ok := app.fxLogger.(*fxevent.ConsoleLogger); ok && l.W != nil {
+			w = l.W
+		}
+		return app.Dump(w)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("app: unable to dump: %w", err)
+	}
+
+	name := app.name
+	if name == "" {
+		name = "app"
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.dump", name, time.Now().Format("20060102T150405.000000000")))
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("app: unable to dump: %w", err)
+	}
+	if err = app.Dump(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("app: unable to dump: %w", err)
+	}
+	return f.Close()
+}
+
+// dumper is implemented by apps that can write diagnostic dumps.
+type dumper interface {
+	dumpTo(dir string) error
+}
+
+// SignalDump writes a diagnostic Dump and keeps the app running; map it to
+// SIGQUIT or SIGUSR2 to debug hung services. Each signal creates a new
+// timestamped file in dir; an empty dir writes to the console fx logger's
+// output, or to stderr for other loggers.
+func SignalDump(dir string) SignalAction {
+	return func(event *SignalEvent) error {
+		d, ok := event.App.(dumper)
+		if !ok {
+			return fmt.Errorf("app: %T cannot dump", event.App)
+		}
+		return d.dumpTo(dir)
+	}
+}
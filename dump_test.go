@@ -0,0 +1,99 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/rumorsflow/app"
+)
+
+func TestDumpReportsStuckStopHook(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	a := newStartedApp(t, fx.Invoke(func(lc fx.Lifecycle) {
+		lc.Append(fx.StopHook(func() {
+			close(entered)
+			<-release
+		}))
+	}))
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- a.Stop(context.Background()) }()
+	waitClosed(t, entered, "stop hook did not run")
+
+	var buf bytes.Buffer
+	if err := a.Dump(&buf); err != nil {
+		t.Fatalf("Dump() = %v", err)
+	}
+	close(release)
+
+	out := buf.String()
+	for _, want := range []string{
+		"app: test-app 0.0.1\n",
+		"state: stopping\n",
+		"  OnStop handlers for ",
+		"  fx OnStop hook ",
+		"goroutine ",
+		"heap profile: ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Dump() missing %q in:\n%s", want, out[:min(len(out), 2000)])
+		}
+	}
+
+	if err := <-stopped; err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+
+	buf.Reset()
+	if err := a.Dump(&buf); err != nil {
+		t.Fatalf("Dump() = %v", err)
+	}
+	if !strings.Contains(buf.String(), "executing:\n  none\n") {
+		t.Errorf("Dump() after stop still reports executing hooks")
+	}
+}
+
+func TestRunDumpsOnSignal(t *testing.T) {
+	// Same rationale as the SIGTERM safety net in app_test.go.
+	safety := make(chan os.Signal, 1)
+	signal.Notify(safety, syscall.SIGUSR2)
+
+	dir := t.TempDir()
+	a := signalApp(map[os.Signal]app.SignalAction{syscall.SIGUSR2: app.SignalDump(dir)})
+	runErr, started := startRun(t, a)
+	waitClosed(t, started, "app did not start")
+
+	dumped := make(chan struct{})
+	go func() {
+		for {
+			if files, _ := filepath.Glob(filepath.Join(dir, "app-*.dump")); len(files) > 0 {
+				close(dumped)
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	sendUntil(t, syscall.SIGUSR2, dumped)
+
+	if s := a.State(); s != app.StateRunning {
+		t.Errorf("State() = %v after dump, want running", s)
+	}
+
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if err := waitErr(t, runErr); err != nil {
+		t.Errorf("Run() = %v, want nil", err)
+	}
+}
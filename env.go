@@ -1,16 +1,158 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 
+	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
 )
 
-const envDotenv = "DOTENV_PATH"
+// DotenvCascade lists the dotenv files of environment env in increasing
+// precedence, the usual cascade for Config.DotenvFiles:
+//
+//	.env              shared defaults, committed
+//	.env.local        local overrides, except in the test environment
+//	.env.{env}        environment defaults, committed
+//	.env.{env}.local  local environment overrides
+//
+// Without env only .env and .env.local are listed. .env.local is left out
+// for env "test" so tests run the same everywhere.
+//
+//	app.Config{DotenvFiles: app.DotenvCascade(os.Getenv("APP_ENV"))}
+//
+// The package no longer loads .env and the file named by DOTENV_PATH when it
+// is imported. Appending that file to the cascade keeps it working:
+//
+//	files := app.DotenvCascade(os.Getenv("APP_ENV"))
+//	if path, ok := os.LookupEnv("DOTENV_PATH"); ok {
+//		files = append(files, path)
+//	}
+func DotenvCascade(env string) []string {
+	files := []string{".env"}
+	if env != "test" {
+		files = append(files, ".env.local")
+	}
+	if env != "" {
+		files = append(files, ".env."+env, ".env."+env+".local")
+	}
+	return files
+}
+
+// readDotenv reads files into one set of values, later files overriding
+// earlier ones. Missing files are skipped, except the first one when
+// baseRequired is set.
+func readDotenv(files []string, baseRequired bool) (map[string]string, error) {
+	values := make(map[string]string)
+	for i, file := range files {
+		v, err := godotenv.Read(file)
+		if err != nil {
+			if (i > 0 || !baseRequired) && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("app: failed to read dotenv file %s: %w", file, err)
+		}
+		maps.Copy(values, v)
+	}
+	return values, nil
+}
+
+// loadDotenv applies the configured dotenv files and returns the env options
+// LoadConfig parses with. Variables already set win unless DotenvOverride is
+// set. The values go into the process environment, or with DotenvScoped only
+// into the returned options.
+//
+// Variables an earlier load set into the process environment do not count as
+// already set, so reloads pick up edited files, and they are unset again once
+// the files drop them. Either only holds while the variable keeps the value
+// the load set.
+func (app *BaseApp) loadDotenv() (env.Options, error) {
+	opts := app.envOptions
+	if len(app.dotenvFiles) == 0 {
+		return opts, nil
+	}
+
+	values, err := readDotenv(app.dotenvFiles, app.dotenvRequired)
+	if err != nil {
+		return opts, err
+	}
+
+	if app.dotenvScoped {
+		if opts.Environment != nil {
+			opts.Environment = maps.Clone(opts.Environment)
+		} else {
+			opts.Environment = env.ToMap(os.Environ())
+		}
+		for key, value := range values {
+			if _, ok := opts.Environment[key]; !ok || app.dotenvOverride {
+				opts.Environment[key] = value
+			}
+		}
+		return opts, nil
+	}
+
+	app.dotenvMu.Lock()
+	defer app.dotenvMu.Unlock()
+
+	set := make(map[string]string, len(values))
+	for key, value := range values {
+		if current, ok := os.LookupEnv(key); ok && !app.dotenvOverride && !app.setByDotenv(key, current) {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return opts, fmt.Errorf("app: failed to set %s from dotenv: %w", key, err)
+		}
+		set[key] = value
+	}
+
+	for key, value := range app.dotenvSet {
+		if _, ok := set[key]; ok {
+			continue
+		}
+		if current, ok := os.LookupEnv(key); ok && current == value {
+			if err := os.Unsetenv(key); err != nil {
+				return opts, fmt.Errorf("app: failed to unset %s from dotenv: %w", key, err)
+			}
+		}
+	}
+	app.dotenvSet = set
+
+	return opts, nil
+}
+
+// setByDotenv reports whether the variable key still has the value the last
+// loadDotenv set.
+func (app *BaseApp) setByDotenv(key, value string) bool {
+	v, ok := app.dotenvSet[key]
+	return ok && v == value
+}
 
-func init() {
-	_ = godotenv.Load()
-	if dotenv, ok := os.LookupEnv(envDotenv); ok {
-		_ = godotenv.Load(dotenv)
+type envOptionsKey struct{}
+
+// withEnvOptions hands the env options of a LoadConfig call to the decoders.
+func withEnvOptions(ctx context.Context, opts env.Options) context.Context {
+	return context.WithValue(ctx, envOptionsKey{}, opts)
+}
+
+func (app *BaseApp) envOptionsFrom(ctx context.Context) env.Options {
+	if opts, ok := ctx.Value(envOptionsKey{}).(env.Options); ok {
+		return opts
+	}
+	return app.envOptions
+}
+
+// lookupEnv returns the variable lookup of opts: its Environment when set,
+// the process environment otherwise.
+func lookupEnv(opts env.Options) func(string) (string, bool) {
+	if opts.Environment == nil {
+		return os.LookupEnv
+	}
+	return func(key string) (string, bool) {
+		value, ok := opts.Environment[key]
+		return value, ok
 	}
 }
@@ -0,0 +1,157 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/rumorsflow/app"
+)
+
+// unsetenv clears keys for the test, restoring them afterwards even when the
+// code under test sets them.
+func unsetenv(t *testing.T, keys ...string) {
+	t.Helper()
+
+	for _, key := range keys {
+		t.Setenv(key, "")
+		if err := os.Unsetenv(key); err != nil {
+			t.Fatalf("Unsetenv() = %v", err)
+		}
+	}
+}
+
+func writeDotenv(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile() = %v", err)
+		}
+	}
+}
+
+func TestDotenvCascade(t *testing.T) {
+	tests := []struct {
+		env  string
+		want []string
+	}{
+		{"", []string{".env", ".env.local"}},
+		{"prod", []string{".env", ".env.local", ".env.prod", ".env.prod.local"}},
+		{"test", []string{".env", ".env.test", ".env.test.local"}},
+	}
+	for _, tt := range tests {
+		if got := app.DotenvCascade(tt.env); !slices.Equal(got, tt.want) {
+			t.Errorf("DotenvCascade(%q) = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigDotenv(t *testing.T) {
+	t.Chdir(t.TempDir())
+	unsetenv(t, "DOTENV_TEST_ADDR", "DOTENV_TEST_PORT")
+	writeDotenv(t, map[string]string{
+		".env":            "DOTENV_TEST_ADDR=shared\nDOTENV_TEST_PORT=1",
+		".env.local":      "DOTENV_TEST_ADDR=local",
+		".env.prod":       "DOTENV_TEST_PORT=2",
+		".env.prod.local": "DOTENV_TEST_ADDR=prod-local",
+	})
+
+	a := configApp(app.Config{EnvPrefix: "DOTENV_TEST_", DotenvFiles: app.DotenvCascade("prod")})
+
+	var cfg netConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if cfg.Addr != "prod-local" || cfg.Port != 2 {
+		t.Errorf("LoadConfig() = %+v, want the most specific file to win", cfg)
+	}
+}
+
+func TestLoadConfigDotenvEnvironmentWins(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeDotenv(t, map[string]string{".env": "DOTENV_TEST_ADDR=file"})
+
+	for _, override := range []bool{false, true} {
+		t.Setenv("DOTENV_TEST_ADDR", "env")
+
+		a := configApp(app.Config{EnvPrefix: "DOTENV_TEST_", DotenvFiles: []string{".env"}, DotenvOverride: override})
+
+		var cfg netConfig
+		if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+			t.Fatalf("LoadConfig() = %v", err)
+		}
+		want := map[bool]string{false: "env", true: "file"}[override]
+		if cfg.Addr != want {
+			t.Errorf("override %v: Addr = %q, want %q", override, cfg.Addr, want)
+		}
+	}
+}
+
+func TestLoadConfigDotenvRequired(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	a := configApp(app.Config{DotenvFiles: []string{".env.missing"}})
+	if err := a.LoadConfig(context.Background(), &netConfig{}); err != nil {
+		t.Errorf("LoadConfig() = %v, want a missing optional file skipped", err)
+	}
+
+	a = configApp(app.Config{DotenvFiles: []string{".env.missing"}, DotenvRequired: true})
+	if err := a.LoadConfig(context.Background(), &netConfig{}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("LoadConfig() = %v, want %v", err, fs.ErrNotExist)
+	}
+
+	writeDotenv(t, map[string]string{".env": ""})
+	a = configApp(app.Config{DotenvFiles: app.DotenvCascade("prod"), DotenvRequired: true})
+	if err := a.LoadConfig(context.Background(), &netConfig{}); err != nil {
+		t.Errorf("LoadConfig() = %v, want only the base file required", err)
+	}
+}
+
+func TestLoadConfigDotenvReload(t *testing.T) {
+	t.Chdir(t.TempDir())
+	unsetenv(t, "DOTENV_TEST_ADDR", "DOTENV_TEST_PORT")
+	writeDotenv(t, map[string]string{".env": "DOTENV_TEST_ADDR=before\nDOTENV_TEST_PORT=1"})
+
+	a := configApp(app.Config{EnvPrefix: "DOTENV_TEST_", DotenvFiles: []string{".env"}})
+
+	var cfg netConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+
+	writeDotenv(t, map[string]string{".env": "DOTENV_TEST_ADDR=after"})
+	cfg = netConfig{}
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if cfg.Addr != "after" || cfg.Port != 0 {
+		t.Errorf("LoadConfig() = %+v, want the edited file applied", cfg)
+	}
+	if v, ok := os.LookupEnv("DOTENV_TEST_PORT"); ok {
+		t.Errorf("DOTENV_TEST_PORT = %q, want it unset with the file no longer setting it", v)
+	}
+}
+
+func TestLoadConfigDotenvScoped(t *testing.T) {
+	t.Chdir(t.TempDir())
+	unsetenv(t, "DOTENV_TEST_ADDR")
+	t.Setenv("DOTENV_TEST_PORT", "1")
+	writeDotenv(t, map[string]string{".env": "DOTENV_TEST_ADDR=file\nDOTENV_TEST_PORT=2"})
+
+	a := configApp(app.Config{EnvPrefix: "DOTENV_TEST_", DotenvFiles: []string{".env"}, DotenvScoped: true})
+
+	var cfg netConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if cfg.Addr != "file" || cfg.Port != 1 {
+		t.Errorf("LoadConfig() = %+v, want the file's addr and the environment's port", cfg)
+	}
+	if v, ok := os.LookupEnv("DOTENV_TEST_ADDR"); ok {
+		t.Errorf("DOTENV_TEST_ADDR = %q leaked into the process environment", v)
+	}
+}
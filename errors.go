@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Sentinels classifying lifecycle failures. The errors returned by the app
+// wrap one of them together with the underlying cause, so both errors.Is
+// against the sentinel and against the cause hold.
+var (
+	ErrConfigValidation   = errors.New("app: failed to validate config")
+	ErrBoot               = errors.New("app: unable to boot")
+	ErrStartTimeout       = errors.New("app: start timed out")
+	ErrStopTimeout        = errors.New("app: stop timed out")
+	ErrNotBooted          = errors.New("app: not booted")
+	ErrRestartUnsupported = errors.New("app: restart is not supported")
+)
+
+// Exit codes after sysexits.h.
+const (
+	ExitOK          = 0
+	ExitFailure     = 1
+	ExitUnavailable = 69
+	ExitSoftware    = 70
+	ExitTempFail    = 75
+	ExitConfig      = 78
+)
+
+// ExitCode maps an error returned by the app to a process exit code:
+//
+//	nil                    ExitOK
+//	ErrConfigValidation    ExitConfig
+//	ErrStartTimeout        ExitTempFail
+//	ErrStopTimeout         ExitTempFail
+//	ErrRestartUnsupported  ExitUnavailable
+//	ErrNotBooted, ErrBoot  ExitSoftware
+//	*PanicError            ExitSoftware
+//	anything else          ExitFailure
+//
+// The first match wins, so a boot failing on config validation exits with
+// ExitConfig.
+//
+//	if err := a.Run(ctx); err != nil {
+//		log.Print(err)
+//		os.Exit(app.ExitCode(err))
+//	}
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrConfigValidation):
+		return ExitConfig
+	case errors.Is(err, ErrStartTimeout), errors.Is(err, ErrStopTimeout):
+		return ExitTempFail
+	case errors.Is(err, ErrRestartUnsupported):
+		return ExitUnavailable
+	case errors.Is(err, ErrNotBooted), errors.Is(err, ErrBoot), errors.As(err, new(*PanicError)):
+		return ExitSoftware
+	default:
+		return ExitFailure
+	}
+}
+
+// wrapError tags err with sentinel, prefixing its message.
+func wrapError(sentinel, err error) error {
+	if err == nil || errors.Is(err, sentinel) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
+
+// markError classifies err as sentinel without changing its message, for
+// causes that already say what failed.
+func markError(sentinel, err error) error {
+	if err == nil || errors.Is(err, sentinel) {
+		return err
+	}
+	return &classifiedError{sentinel: sentinel, err: err}
+}
+
+type classifiedError struct {
+	sentinel error
+	err      error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() []error {
+	return []error{e.sentinel, e.err}
+}
+
+// timeoutError tags err with sentinel if ctx, the phase context, expired.
+func timeoutError(ctx context.Context, sentinel, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return wrapError(sentinel, err)
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/rumorsflow/app"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, app.ExitOK},
+		{errSentinel, app.ExitFailure},
+		{fmt.Errorf("%w: %w", app.ErrConfigValidation, errSentinel), app.ExitConfig},
+		{fmt.Errorf("%w: %w", app.ErrBoot, app.ErrConfigValidation), app.ExitConfig},
+		{app.ErrBoot, app.ExitSoftware},
+		{app.ErrNotBooted, app.ExitSoftware},
+		{app.ErrStartTimeout, app.ExitTempFail},
+		{app.ErrStopTimeout, app.ExitTempFail},
+		{app.ErrRestartUnsupported, app.ExitUnavailable},
+		{&app.PanicError{Value: "boom"}, app.ExitSoftware},
+	}
+	for _, tt := range tests {
+		if got := app.ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestBootConfigValidationError(t *testing.T) {
+	a := configApp(app.Config{ConfigRaw: []byte(`{"fail":true}`)})
+	a.OnBoot().BindFunc(app.LoadConfig[validatedConfig]())
+
+	err := a.Boot(context.Background())
+	for _, target := range []error{app.ErrBoot, app.ErrConfigValidation, errSentinel} {
+		if !errors.Is(err, target) {
+			t.Errorf("Boot() = %v, want errors.Is %v", err, target)
+		}
+	}
+	if code := app.ExitCode(err); code != app.ExitConfig {
+		t.Errorf("ExitCode() = %d, want %d", code, app.ExitConfig)
+	}
+}
+
+func TestBootGraphErrorIsErrBoot(t *testing.T) {
+	a := newApp(t, fx.Invoke(func(*depA) {}))
+
+	err := a.Boot(context.Background())
+	if !errors.Is(err, app.ErrBoot) {
+		t.Errorf("Boot() = %v, want %v", err, app.ErrBoot)
+	}
+	var graphErr *app.GraphError
+	if !errors.As(err, &graphErr) {
+		t.Errorf("Boot() = %v, want *app.GraphError", err)
+	}
+}
+
+func TestStartNotBootedIsErrNotBooted(t *testing.T) {
+	err := newApp(t).Start(context.Background())
+	if !errors.Is(err, app.ErrNotBooted) {
+		t.Errorf("Start() = %v, want %v", err, app.ErrNotBooted)
+	}
+}
+
+func blockingApp(t *testing.T, hook fx.Hook) *app.BaseApp {
+	t.Helper()
+
+	a := app.NewBaseApp(app.Config{
+		StartTimeout: 50 * time.Millisecond,
+		StopTimeout:  50 * time.Millisecond,
+	})
+	a.OnBoot().BindFunc(app.Options(fx.Invoke(func(lc fx.Lifecycle) { lc.Append(hook) })))
+	if err := a.Boot(context.Background()); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+	return a
+}
+
+func waitDone(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestStartTimeoutError(t *testing.T) {
+	a := blockingApp(t, fx.Hook{OnStart: waitDone})
+
+	err := a.Start(context.Background())
+	if !errors.Is(err, app.ErrStartTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Start() = %v, want %v wrapping the deadline", err, app.ErrStartTimeout)
+	}
+	if code := app.ExitCode(err); code != app.ExitTempFail {
+		t.Errorf("ExitCode() = %d, want %d", code, app.ExitTempFail)
+	}
+}
+
+func TestStopTimeoutError(t *testing.T) {
+	a := blockingApp(t, fx.Hook{OnStop: waitDone})
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	err := a.Stop(context.Background())
+	if !errors.Is(err, app.ErrStopTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop() = %v, want %v wrapping the deadline", err, app.ErrStopTimeout)
+	}
+}
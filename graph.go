@@ -0,0 +1,360 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+)
+
+// Graph describes the fx container assembled from the boot options.
+type Graph struct {
+	Providers []GraphProvider `json:"providers"`
+
+	dot fx.DotGraph
+}
+
+// GraphProvider is a single constructor or supplied value of the container.
+type GraphProvider struct {
+	Constructor string   `json:"constructor"`
+	Outputs     []string `json:"outputs"`
+	// Origin names the OnBoot handler that added the constructor and its
+	// position in the chain, e.g. "OnBoot#2 main.main.func1". It is empty for
+	// what the app and fx provide themselves.
+	Origin string `json:"origin,omitempty"`
+	// Consumers lists the constructors, decorators and invokes that depend
+	// on any of Outputs.
+	Consumers []string `json:"consumers,omitempty"`
+}
+
+// WriteDOT writes the graph in Graphviz DOT format as rendered by fx,
+// including the edges between constructors.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	_, err := io.WriteString(w, string(g.dot)+"\n")
+	return err
+}
+
+// WriteJSON writes the providers with their origins and consumers as an
+// indented JSON document.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
+
+// Grapher is implemented by apps that can describe their fx container
+// without running it, like BaseApp. The graph command of Main uses it.
+type Grapher interface {
+	Graph(ctx context.Context) (*Graph, error)
+}
+
+var _ Grapher = (*BaseApp)(nil)
+
+// Graph runs the OnBoot chain and builds the fx container like Verify, with
+// the same throwaway registries, and returns its dependency graph.
+func (app *BaseApp) Graph(ctx context.Context) (*Graph, error) {
+	var g *Graph
+
+	tracker := &bootTracker{}
+	event := &BootEvent{App: app, Ctx: ctx, Logger: fxevent.NopLogger, tracker: tracker}
+	err := app.OnBoot().Trigger(event, func(event *BootEvent) error {
+		if event.Logger == nil {
+			return errors.New("app: bootstrap fx event logger is nil")
+		}
+
+		rec := &graphRecorder{logger: event.Logger}
+
+		reg := app.dryRunRegistries()
+		defer func() { _ = reg.close() }()
+
+		var dot fx.DotGraph
+		if _, err := app.newFxApp(event, reg, func() fxevent.Logger { return rec }, fx.Populate(&dot)); err != nil {
+			return err
+		}
+
+		g = &Graph{Providers: rec.providers, dot: dot}
+		tracker.attribute(g.Providers)
+		addConsumers(g.Providers, event.Options)
+
+		return event.Next()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// graphRecorder forwards fx events to the boot logger while remembering
+// who provided what.
+type graphRecorder struct {
+	logger    fxevent.Logger
+	mu        sync.Mutex
+	providers []GraphProvider
+}
+
+func (r *graphRecorder) LogEvent(event fxevent.Event) {
+	var p GraphProvider
+
+	switch e := event.(type) {
+	case *fxevent.Provided:
+		p = GraphProvider{Constructor: e.ConstructorName, Outputs: e.OutputTypeNames}
+	case *fxevent.Supplied:
+		p = GraphProvider{Constructor: "fx.Supply(" + e.TypeName + ")", Outputs: []string{e.TypeName}}
+	}
+
+	if p.Constructor != "" {
+		r.mu.Lock()
+		r.providers = append(r.providers, p)
+		r.mu.Unlock()
+	}
+
+	r.logger.LogEvent(event)
+}
+
+// bootHandler holds the options one OnBoot handler added to BootEvent.Options.
+type bootHandler struct {
+	origin  string
+	options []fx.Option
+}
+
+// bootTracker splits the boot options by the OnBoot handler that added them
+// while Graph runs the chain.
+type bootTracker struct {
+	calls    int
+	seen     int
+	handlers []bootHandler
+}
+
+// next is called by BootEvent.Next and credits the options added since the
+// previous call to the handler calling it. The first call comes from the hook
+// itself.
+func (t *bootTracker) next(options []fx.Option) {
+	t.calls++
+	if t.seen > len(options) {
+		t.seen = len(options)
+	}
+	if t.calls > 1 && len(options) > t.seen {
+		name := callerName(2)
+		if name == "" {
+			name = "unknown"
+		}
+		t.handlers = append(t.handlers, bootHandler{
+			origin:  fmt.Sprintf("OnBoot#%d %s", t.calls-1, name),
+			options: slices.Clone(options[t.seen:]),
+		})
+	}
+	t.seen = len(options)
+}
+
+// attribute sets the origin of the providers the tracked handlers added. fx
+// reports providers only for a whole container, so the options of every
+// handler go into a container of their own and its providers are matched by
+// name and outputs. fx's own providers are in every container and stay
+// unattributed.
+func (t *bootTracker) attribute(providers []GraphProvider) {
+	builtin := make(map[string]bool)
+	for _, p := range providersOf() {
+		builtin[providerKey(p)] = true
+	}
+
+	origins := make(map[string]string)
+	for _, h := range t.handlers {
+		for _, p := range providersOf(h.options...) {
+			if key := providerKey(p); !builtin[key] && origins[key] == "" {
+				origins[key] = h.origin
+			}
+		}
+	}
+
+	for i := range providers {
+		providers[i].Origin = origins[providerKey(providers[i])]
+	}
+}
+
+// errProvidersOnly stops the container of providersOf before it invokes
+// anything.
+var errProvidersOnly = errors.New("app: providers only")
+
+// providersOf lists the providers of options without running them. fx runs
+// the invokes of the modules first, in order, so the leading module stops the
+// container before any invoke of options, and constructors only run when
+// invoked. Other errors are ignored too: the options of a single handler
+// often depend on other handlers.
+func providersOf(options ...fx.Option) []GraphProvider {
+	rec := &graphRecorder{logger: fxevent.NopLogger}
+	fx.New(
+		fx.Module("graph", fx.Invoke(func() error { return errProvidersOnly })),
+		fx.Options(options...),
+		fx.WithLogger(func() fxevent.Logger { return rec }),
+	)
+	return rec.providers
+}
+
+func providerKey(p GraphProvider) string {
+	return p.Constructor + "\x00" + strings.Join(p.Outputs, "\x00")
+}
+
+// addConsumers lists for every provider the functions among options that
+// take one of its outputs. fx reports what a constructor provides but not
+// what it takes, so the parameters come from the functions themselves, keyed
+// like fx names outputs.
+func addConsumers(providers []GraphProvider, options []fx.Option) {
+	consumers := make(map[string][]string)
+	for _, target := range targetsOf(options) {
+		for _, key := range inputKeys(reflect.TypeOf(target.fn)) {
+			if !slices.Contains(consumers[key], target.name) {
+				consumers[key] = append(consumers[key], target.name)
+			}
+		}
+	}
+
+	for i, p := range providers {
+		for _, output := range p.Outputs {
+			for _, name := range consumers[output] {
+				if !slices.Contains(providers[i].Consumers, name) {
+					providers[i].Consumers = append(providers[i].Consumers, name)
+				}
+			}
+		}
+	}
+}
+
+// targetsOf returns the functions options hand to fx.Provide, fx.Invoke and
+// fx.Decorate, with the fx.Annotate annotations built in. fx keeps them in
+// exported fields of its options; options it keeps private, like those of
+// fx.Module, are not looked into.
+func targetsOf(options []fx.Option) []graphTarget {
+	optionType := reflect.TypeFor[fx.Option]()
+
+	var targets []graphTarget
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Slice:
+			for i := range v.Len() {
+				walk(v.Index(i))
+			}
+		case reflect.Struct:
+			for i := range v.NumField() {
+				field := v.Type().Field(i)
+				if !field.IsExported() {
+					continue
+				}
+				switch t := field.Type; {
+				case t.Implements(optionType), t.Kind() == reflect.Slice && t.Elem().Implements(optionType):
+					walk(v.Field(i))
+				case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Interface:
+					for j := range v.Field(i).Len() {
+						if target, ok := buildTarget(v.Field(i).Index(j)); ok {
+							targets = append(targets, target)
+						}
+					}
+				}
+			}
+		}
+	}
+	for _, option := range options {
+		walk(reflect.ValueOf(option))
+	}
+	return targets
+}
+
+// graphTarget is a function handed to fx, named like fx names it in events.
+type graphTarget struct {
+	name string
+	fn   any
+}
+
+// buildTarget returns the function v holds, building it when v is the
+// result of fx.Annotate.
+func buildTarget(v reflect.Value) (graphTarget, bool) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return graphTarget{}, false
+	}
+	if v.Kind() == reflect.Func {
+		return graphTarget{name: funcName(v.Interface()), fn: v.Interface()}, !v.IsNil()
+	}
+
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	b, ok := ptr.Interface().(interface{ Build() (any, error) })
+	if !ok {
+		return graphTarget{}, false
+	}
+	fn, err := b.Build()
+	if err != nil || reflect.TypeOf(fn).Kind() != reflect.Func {
+		return graphTarget{}, false
+	}
+	return graphTarget{name: fmt.Sprint(v.Interface()), fn: fn}, true
+}
+
+// inputKeys names the values a function of type t takes, in the format fx
+// uses for outputs: the type, then its name or group in brackets. The
+// fields of fx.In parameters count as parameters of their own.
+func inputKeys(t reflect.Type) []string {
+	if t.Kind() != reflect.Func {
+		return nil
+	}
+
+	var keys []string
+	var add func(t reflect.Type, tag reflect.StructTag)
+	add = func(t reflect.Type, tag reflect.StructTag) {
+		if isInStruct(t) {
+			for i := range t.NumField() {
+				if field := t.Field(i); field.IsExported() && !field.Anonymous {
+					add(field.Type, field.Tag)
+				}
+			}
+			return
+		}
+
+		switch group, _, _ := strings.Cut(tag.Get("group"), ","); {
+		case group != "" && t.Kind() == reflect.Slice:
+			keys = append(keys, fmt.Sprintf("%v[group = %q]", t.Elem(), group))
+		case tag.Get("name") != "":
+			keys = append(keys, fmt.Sprintf("%v[name = %q]", t, tag.Get("name")))
+		default:
+			keys = append(keys, t.String())
+		}
+	}
+
+	n := t.NumIn()
+	if t.IsVariadic() {
+		// fx leaves variadic parameters empty.
+		n--
+	}
+	for i := range n {
+		add(t.In(i), "")
+	}
+	return keys
+}
+
+func isInStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := range t.NumField() {
+		if field := t.Field(i); field.Anonymous && field.Type == reflect.TypeFor[fx.In]() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/fx"
+
+	"github.com/rumorsflow/app"
+)
+
+func newDepA() *depA            { return &depA{} }
+func newDepB(*depA) *depB       { return &depB{} }
+func newNamed(*depB) string     { return "" }
+func newMember() int            { return 1 }
+func newSum([]int, string) uint { return 0 }
+
+func TestGraph(t *testing.T) {
+	var invokes atomic.Int32
+	a := newApp(t, fx.Provide(newDepA, newDepB), fx.Invoke(func(*depB) { invokes.Add(1) }))
+	a.OnBoot().BindFunc(func(event *app.BootEvent) error {
+		event.Options = append(event.Options, fx.Provide(
+			fx.Annotate(newNamed, fx.ResultTags(`name:"named"`)),
+			fx.Annotate(newMember, fx.ResultTags(`group:"members"`)),
+			fx.Annotate(newSum, fx.ParamTags(`group:"members"`, `name:"named"`)),
+		))
+		return event.Next()
+	})
+
+	g, err := a.Graph(context.Background())
+	if err != nil {
+		t.Fatalf("Graph() = %v", err)
+	}
+
+	find := func(output string) app.GraphProvider {
+		t.Helper()
+		for _, p := range g.Providers {
+			if slices.Contains(p.Outputs, output) {
+				return p
+			}
+		}
+		t.Fatalf("no provider of %s in %+v", output, g.Providers)
+		return app.GraphProvider{}
+	}
+
+	depA := find("*app_test.depA")
+	if !strings.HasSuffix(depA.Constructor, "app_test.newDepA()") {
+		t.Errorf("depA constructor = %q", depA.Constructor)
+	}
+	if !strings.HasPrefix(depA.Origin, "OnBoot#1 ") || !strings.Contains(depA.Origin, "app.Options") {
+		t.Errorf("depA origin = %q, want the first handler, app.Options", depA.Origin)
+	}
+	if len(depA.Consumers) != 1 || !strings.Contains(depA.Consumers[0], "newDepB") {
+		t.Errorf("depA consumers = %q, want newDepB", depA.Consumers)
+	}
+	if depB := find("*app_test.depB"); len(depB.Consumers) != 2 {
+		t.Errorf("depB consumers = %q, want newNamed and the invoke", depB.Consumers)
+	}
+	for _, output := range []string{`string[name = "named"]`, `int[group = "members"]`} {
+		if p := find(output); len(p.Consumers) != 1 || !strings.Contains(p.Consumers[0], "newSum") {
+			t.Errorf("%s consumers = %q, want newSum", output, p.Consumers)
+		}
+	}
+	if sum := find("uint"); !strings.HasPrefix(sum.Origin, "OnBoot#2 ") || !strings.Contains(sum.Origin, "app_test.TestGraph") {
+		t.Errorf("newSum origin = %q, want the second handler, the test function", sum.Origin)
+	}
+	if lc := find("fx.Lifecycle"); lc.Origin != "" {
+		t.Errorf("fx.Lifecycle origin = %q, want none", lc.Origin)
+	}
+	if n := invokes.Load(); n != 1 {
+		t.Errorf("invoke ran %d times, want once", n)
+	}
+
+	var dot bytes.Buffer
+	if err := g.WriteDOT(&dot); err != nil {
+		t.Fatalf("WriteDOT() = %v", err)
+	}
+	if !strings.HasPrefix(dot.String(), "digraph {") || !strings.Contains(dot.String(), `-> "*app_test.depA"`) {
+		t.Errorf("WriteDOT() = %q, want a digraph with the depB edge", dot.String())
+	}
+
+	var doc bytes.Buffer
+	if err := g.WriteJSON(&doc); err != nil {
+		t.Fatalf("WriteJSON() = %v", err)
+	}
+	var decoded struct {
+		Providers []app.GraphProvider `json:"providers"`
+	}
+	if err := json.Unmarshal(doc.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteJSON() produced invalid JSON: %v", err)
+	}
+	if len(decoded.Providers) != len(g.Providers) {
+		t.Errorf("JSON providers = %d, want %d", len(decoded.Providers), len(g.Providers))
+	}
+}
+
+func TestGraphThenBoot(t *testing.T) {
+	var runs atomic.Int32
+	a := newApp(t, registeringInvoke(&runs))
+
+	ctx := context.Background()
+	if _, err := a.Graph(ctx); err != nil {
+		t.Fatalf("Graph() = %v", err)
+	}
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() after Graph = %v", err)
+	}
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if err := a.Stop(ctx); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if n := runs.Load(); n != 1 {
+		t.Errorf("worker ran %d times, want once", n)
+	}
+}
+
+func TestExecuteGraph(t *testing.T) {
+	a := newApp(t, fx.Provide(newDepA))
+
+	code, out, stderr := execute(t, a, "graph", "--format", "json")
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	if !strings.Contains(out, `"*app_test.depA"`) {
+		t.Errorf("graph output = %s, want depA provider", out)
+	}
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// CheckKind classifies a health check; a check may be both.
+type CheckKind int
+
+const (
+	Readiness CheckKind = 1 << iota
+	Liveness
+)
+
+// HealthStatus is the outcome of a check or of a whole report.
+type HealthStatus string
+
+const (
+	StatusUp       HealthStatus = "up"
+	StatusDegraded HealthStatus = "degraded"
+	StatusDown     HealthStatus = "down"
+)
+
+const defaultCheckTimeout = 5 * time.Second
+
+// Check is a named health probe registered with Health.
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+	// Timeout bounds a single run; 5s when zero.
+	Timeout time.Duration
+	// Critical failures take the report down; others only degrade it.
+	Critical bool
+	// Kind selects the reports the check takes part in; Readiness when zero.
+	Kind CheckKind
+}
+
+type CheckResult struct {
+	Name     string        `json:"name"`
+	Status   HealthStatus  `json:"status"`
+	Critical bool          `json:"critical"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+type HealthReport struct {
+	Status HealthStatus  `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Health aggregates the checks registered by components into liveness and
+// readiness reports. It is supplied to the fx graph by Boot.
+//
+// Readiness is tied to the lifecycle: the app reports not ready unless it is
+// running, so a beginning Stop or Restart drains it from load balancers
+// before any component stops.
+type Health struct {
+	mu     sync.RWMutex
+	checks []Check
+	state  func() State
+}
+
+func newHealth(state func() State) *Health {
+	return &Health{state: state}
+}
+
+// Register adds a check. Names must be unique.
+func (h *Health) Register(check Check) error {
+	if check.Name == "" || check.Check == nil {
+		return errors.New("app: health check needs a name and a func")
+	}
+	if check.Kind == 0 {
+		check.Kind = Readiness
+	}
+	if check.Timeout <= 0 {
+		check.Timeout = defaultCheckTimeout
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if slices.ContainsFunc(h.checks, func(c Check) bool { return c.Name == check.Name }) {
+		return fmt.Errorf("app: health check %q already registered", check.Name)
+	}
+	h.checks = append(h.checks, check)
+
+	return nil
+}
+
+// Liveness runs the liveness checks.
+func (h *Health) Liveness(ctx context.Context) HealthReport {
+	return h.run(ctx, Liveness)
+}
+
+// Readiness runs the readiness checks. Outside StateRunning the report is
+// down with a "lifecycle" result naming the current state.
+func (h *Health) Readiness(ctx context.Context) HealthReport {
+	if state := h.state(); state != StateRunning {
+		return HealthReport{
+			Status: StatusDown,
+			Checks: []CheckResult{{
+				Name:     "lifecycle",
+				Status:   StatusDown,
+				Critical: true,
+				Error:    "app is " + state.String(),
+			}},
+		}
+	}
+	return h.run(ctx, Readiness)
+}
+
+func (h *Health) run(ctx context.Context, kind CheckKind) HealthReport {
+	h.mu.RLock()
+	var checks []Check
+	for _, c := range h.checks {
+		if c.Kind&kind != 0 {
+			checks = append(checks, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	report := HealthReport{Status: StatusUp, Checks: make([]CheckResult, len(checks))}
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Go(func() {
+			report.Checks[i] = runCheck(ctx, c)
+		})
+	}
+	wg.Wait()
+
+	for _, r := range report.Checks {
+		switch {
+		case r.Status == StatusUp:
+		case r.Critical:
+			report.Status = StatusDown
+		case report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+func runCheck(ctx context.Context, c Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() { errc <- c.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	r := CheckResult{Name: c.Name, Status: StatusUp, Critical: c.Critical, Duration: time.Since(start)}
+	if err != nil {
+		r.Status = StatusDown
+		r.Error = err.Error()
+	}
+	return r
+}
+
+func (app *BaseApp) Health() *Health {
+	return app.health
+}
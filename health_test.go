@@ -0,0 +1,93 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/rumorsflow/app"
+)
+
+func TestHealthReports(t *testing.T) {
+	a := newStartedApp(t, fx.Invoke(func(h *app.Health) error {
+		return errors.Join(
+			h.Register(app.Check{Name: "db", Critical: true, Check: func(context.Context) error { return nil }}),
+			h.Register(app.Check{Name: "cache", Check: func(context.Context) error { return errSentinel }}),
+			h.Register(app.Check{
+				Name:     "loop",
+				Kind:     app.Liveness,
+				Critical: true,
+				Timeout:  10 * time.Millisecond,
+				Check: func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			}),
+		)
+	}))
+
+	ctx := context.Background()
+
+	ready := a.Health().Readiness(ctx)
+	if ready.Status != app.StatusDegraded {
+		t.Errorf("Readiness().Status = %v, want %v", ready.Status, app.StatusDegraded)
+	}
+	if len(ready.Checks) != 2 || ready.Checks[1].Error != errSentinel.Error() {
+		t.Errorf("Readiness().Checks = %+v, want db and failing cache", ready.Checks)
+	}
+
+	live := a.Health().Liveness(ctx)
+	if live.Status != app.StatusDown {
+		t.Errorf("Liveness().Status = %v, want %v", live.Status, app.StatusDown)
+	}
+	if len(live.Checks) != 1 || live.Checks[0].Name != "loop" {
+		t.Errorf("Liveness().Checks = %+v, want only loop", live.Checks)
+	}
+}
+
+func TestHealthRegisterDuplicate(t *testing.T) {
+	h := newApp(t).Health()
+	check := app.Check{Name: "db", Check: func(context.Context) error { return nil }}
+
+	if err := h.Register(check); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	if err := h.Register(check); err == nil {
+		t.Error("second Register() = nil, want duplicate error")
+	}
+}
+
+func TestHealthNotReadyWhileStopping(t *testing.T) {
+	a := newApp(t)
+
+	ctx := context.Background()
+	if got := a.Health().Readiness(ctx).Status; got != app.StatusDown {
+		t.Errorf("Readiness() before Start = %v, want %v", got, app.StatusDown)
+	}
+
+	var atStop app.HealthStatus
+	a.OnStop().BindFunc(func(e *app.StopEvent) error {
+		atStop = a.Health().Readiness(e.Ctx).Status
+		return e.Next()
+	})
+
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if got := a.Health().Readiness(ctx).Status; got != app.StatusUp {
+		t.Errorf("Readiness() while running = %v, want %v", got, app.StatusUp)
+	}
+
+	if err := a.Stop(ctx); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if atStop != app.StatusDown {
+		t.Errorf("Readiness() in OnStop = %v, want %v", atStop, app.StatusDown)
+	}
+}
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// envListeners carries the listener manifest across an exec restart as a
+// comma-separated list of fd=network://address entries.
+const envListeners = "APP_LISTENERS"
+
+type filer interface {
+	File() (*os.File, error)
+}
+
+// Listeners hands out net.Listeners and keeps a duplicate of every socket it
+// created or adopted. Restart passes those duplicates to the re-exec'ed
+// process, which adopts them instead of binding anew, so clients connecting
+// during the gap wait in the kernel backlog rather than being refused.
+//
+// It is supplied to the fx graph by Boot; components obtain it as
+// *app.Listeners and call Listen instead of net.Listen.
+type Listeners struct {
+	mu        sync.Mutex
+	keys      []string
+	files     map[string]*os.File
+	inherited map[string]struct{}
+}
+
+// newListeners adopts the sockets passed by a previous process, if any. The
+// manifest is removed from the environment so unrelated child processes do
+// not mistake their own descriptors for handed-off sockets.
+func newListeners() *Listeners {
+	l := emptyListeners()
+
+	manifest, ok := os.LookupEnv(envListeners)
+	if !ok {
+		return l
+	}
+	_ = os.Unsetenv(envListeners)
+
+	for _, entry := range strings.Split(manifest, ",") {
+		fdStr, key, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		fd, err := strconv.Atoi(fdStr)
+		if err != nil || fd < 0 {
+			continue
+		}
+
+		syscall.CloseOnExec(fd)
+
+		l.keys = append(l.keys, key)
+		l.files[key] = os.NewFile(uintptr(fd), key)
+		l.inherited[key] = struct{}{}
+	}
+
+	return l
+}
+
+func emptyListeners() *Listeners {
+	return &Listeners{
+		files:     make(map[string]*os.File),
+		inherited: make(map[string]struct{}),
+	}
+}
+
+func listenerKey(network, address string) string {
+	return network + "://" + address
+}
+
+// Listen announces on the local network address like net.Listen. When the
+// process was started by Restart and the previous instance listened on the
+// same network and address, the inherited socket is returned instead.
+func (l *Listeners) Listen(network, address string) (net.Listener, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := listenerKey(network, address)
+	if f, ok := l.files[key]; ok {
+		delete(l.inherited, key)
+		return net.FileListener(f)
+	}
+
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	fl, ok := ln.(filer)
+	if !ok {
+		_ = ln.Close()
+		return nil, fmt.Errorf("app: listener %s cannot be handed off", key)
+	}
+
+	// The socket file must survive the service closing its listener during
+	// a restart; it is removed by close on a plain stop instead.
+	if ul, ok := ln.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+
+	f, err := fl.File()
+	if err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("app: unable to duplicate listener %s: %w", key, err)
+	}
+
+	l.keys = append(l.keys, key)
+	l.files[key] = f
+
+	return ln, nil
+}
+
+// release closes inherited sockets no component asked for once the app has
+// started, so ports dropped from the configuration are freed.
+func (l *Listeners) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key := range l.inherited {
+		_ = l.files[key].Close()
+		delete(l.files, key)
+		l.keys = slices.DeleteFunc(l.keys, func(k string) bool { return k == key })
+	}
+	clear(l.inherited)
+}
+
+// close releases every socket held by the registry and removes unix socket
+// files. It is called on a plain stop, never on restart.
+func (l *Listeners) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for _, key := range l.keys {
+		if err := l.files[key].Close(); err != nil {
+			errs = append(errs, err)
+		}
+
+		network, address, _ := strings.Cut(key, "://")
+		if strings.HasPrefix(network, "unix") && !strings.HasPrefix(address, "@") {
+			if err := os.Remove(address); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	l.keys = nil
+	clear(l.files)
+	clear(l.inherited)
+
+	return errors.Join(errs...)
+}
+
+// manifest clears close-on-exec on every held socket and returns the value
+// of envListeners describing them. It must only be called right before exec.
+func (l *Listeners) manifest() (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := make([]string, 0, len(l.keys))
+	for _, key := range l.keys {
+		rc, err := l.files[key].SyscallConn()
+		if err != nil {
+			return "", err
+		}
+
+		var errno syscall.Errno
+		if err = rc.Control(func(fd uintptr) {
+			_, _, errno = syscall.Syscall(syscall.SYS_FCNTL, fd, syscall.F_SETFD, 0)
+			entries = append(entries, strconv.FormatUint(uint64(fd), 10)+"="+key)
+		}); err != nil {
+			return "", err
+		}
+		if errno != 0 {
+			return "", fmt.Errorf("app: unable to pass listener %s: %w", key, errno)
+		}
+	}
+
+	return strings.Join(entries, ","), nil
+}
@@ -0,0 +1,91 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"go.uber.org/fx"
+
+	"github.com/rumorsflow/app"
+)
+
+func TestListenersSuppliedAndFreedOnStop(t *testing.T) {
+	var addr string
+	a := newStartedApp(t, fx.Invoke(func(l *app.Listeners) error {
+		ln, err := l.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return err
+		}
+		addr = ln.Addr().String()
+		// The registry keeps its own duplicate, so the service closing its
+		// listener must not free the port.
+		return ln.Close()
+	}))
+
+	if ln, err := net.Listen("tcp", addr); err == nil {
+		_ = ln.Close()
+		t.Fatalf("Listen(%s) succeeded while the registry holds the socket", addr)
+	}
+
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("Listen(%s) after Stop = %v, want port freed", addr, err)
+	}
+	_ = ln.Close()
+}
+
+func TestListenersAdoptInherited(t *testing.T) {
+	orig, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	defer orig.Close()
+
+	f, err := orig.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatalf("File() = %v", err)
+	}
+	defer f.Close()
+
+	// The registry takes ownership of the passed descriptor and closes it
+	// on Stop, so hand it a dup rather than f's own.
+	fd, err := syscall.Dup(int(f.Fd()))
+	if err != nil {
+		t.Fatalf("Dup() = %v", err)
+	}
+
+	addr := orig.Addr().String()
+	t.Setenv("APP_LISTENERS", fmt.Sprintf("%d=tcp://%s", fd, addr))
+
+	var adopted net.Listener
+	a := newStartedApp(t, fx.Invoke(func(l *app.Listeners) (err error) {
+		adopted, err = l.Listen("tcp", addr)
+		return err
+	}))
+	defer adopted.Close()
+
+	if _, ok := os.LookupEnv("APP_LISTENERS"); ok {
+		t.Error("APP_LISTENERS still set after adoption")
+	}
+	if got := adopted.Addr().String(); got != addr {
+		t.Errorf("adopted Addr() = %s, want %s", got, addr)
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	defer conn.Close()
+
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+}
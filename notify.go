@@ -0,0 +1,122 @@
+package app
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	envNotifySocket = "NOTIFY_SOCKET"
+	envWatchdogUsec = "WATCHDOG_USEC"
+	envWatchdogPID  = "WATCHDOG_PID"
+)
+
+// notifier speaks the systemd sd_notify protocol: newline separated
+// assignments sent as a single datagram to NOTIFY_SOCKET. Without the
+// variable every call is a no-op, so apps not run under systemd Type=notify
+// are unaffected.
+//
+// The variables are left in the environment on purpose: an exec restart keeps
+// the PID, and the new image has to keep talking to the same socket.
+type notifier struct {
+	addr     *net.UnixAddr
+	watchdog time.Duration
+	once     sync.Once
+}
+
+func newNotifier() *notifier {
+	n := &notifier{}
+
+	socket := os.Getenv(envNotifySocket)
+	if socket == "" {
+		return n
+	}
+	// A leading '@' names an abstract socket, which net translates itself.
+	n.addr = &net.UnixAddr{Name: socket, Net: "unixgram"}
+
+	if pid := os.Getenv(envWatchdogPID); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return n
+	}
+	if usec, err := strconv.ParseInt(os.Getenv(envWatchdogUsec), 10, 64); err == nil && usec > 0 {
+		n.watchdog = time.Duration(usec) * time.Microsecond
+	}
+
+	return n
+}
+
+func (n *notifier) notify(state ...string) error {
+	if n.addr == nil {
+		return nil
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, n.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(strings.Join(state, "\n")))
+	return err
+}
+
+// ready is sent once the app runs. After an exec restart systemd is told the
+// main PID again, since it saw the service reload rather than exit.
+func (n *notifier) ready(restarted bool) {
+	if restarted {
+		_ = n.notify("READY=1", "MAINPID="+strconv.Itoa(os.Getpid()))
+		return
+	}
+	_ = n.notify("READY=1")
+}
+
+// reloading carries MONOTONIC_USEC, which Type=notify-reload services must
+// send along with RELOADING=1.
+func (n *notifier) reloading() {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+		_ = n.notify("RELOADING=1")
+		return
+	}
+	_ = n.notify("RELOADING=1", "MONOTONIC_USEC="+strconv.FormatInt(ts.Nano()/1e3, 10))
+}
+
+func (n *notifier) stopping() {
+	_ = n.notify("STOPPING=1")
+}
+
+// startWatchdog pings systemd at half the configured interval until done is
+// closed. It runs at most once per process image.
+func (n *notifier) startWatchdog(done <-chan struct{}) {
+	if n.addr == nil || n.watchdog <= 0 {
+		return
+	}
+
+	n.once.Do(func() {
+		go func() {
+			ticker := time.NewTicker(n.watchdog / 2)
+			defer ticker.Stop()
+
+			for {
+				_ = n.notify("WATCHDOG=1")
+
+				select {
+				case <-ticker.C:
+				case <-done:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// Notify sends raw sd_notify assignments such as "STATUS=warming caches" to
+// systemd. It does nothing unless NOTIFY_SOCKET is set.
+func (app *BaseApp) Notify(state ...string) error {
+	return app.notifier.notify(state...)
+}
@@ -0,0 +1,154 @@
+package app_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenNotify stands in for systemd: it binds a unixgram socket, points
+// NOTIFY_SOCKET at it and returns the received datagrams.
+func listenNotify(t *testing.T) <-chan string {
+	t.Helper()
+
+	// Unix socket paths are short; t.TempDir may exceed the limit.
+	dir, err := os.MkdirTemp("", "sd")
+	if err != nil {
+		t.Fatalf("os.MkdirTemp() = %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("net.ListenUnixgram() = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	msgs := make(chan string, 64)
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				close(msgs)
+				return
+			}
+			msgs <- string(buf[:n])
+		}
+	}()
+	return msgs
+}
+
+// expectNotify skips watchdog pings and returns the next other message.
+func expectNotify(t *testing.T, msgs <-chan string, prefix string) string {
+	t.Helper()
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case msg := <-msgs:
+			if msg == "WATCHDOG=1" {
+				continue
+			}
+			if !strings.HasPrefix(msg, prefix) {
+				t.Fatalf("notify = %q, want %s", msg, prefix)
+			}
+			return msg
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s", prefix)
+			return ""
+		}
+	}
+}
+
+func TestNotifyLifecycle(t *testing.T) {
+	msgs := listenNotify(t)
+
+	a := newStartedApp(t)
+	expectNotify(t, msgs, "READY=1")
+
+	if err := a.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() = %v", err)
+	}
+	if msg := expectNotify(t, msgs, "RELOADING=1"); !strings.Contains(msg, "\nMONOTONIC_USEC=") {
+		t.Errorf("reload notify = %q, want MONOTONIC_USEC", msg)
+	}
+	if msg := expectNotify(t, msgs, "READY=1"); msg != "READY=1" {
+		t.Errorf("notify after reload = %q, want READY=1", msg)
+	}
+
+	if err := a.Notify("STATUS=busy"); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+	expectNotify(t, msgs, "STATUS=busy")
+
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	expectNotify(t, msgs, "STOPPING=1")
+}
+
+func TestNotifyMainPIDAfterRestart(t *testing.T) {
+	msgs := listenNotify(t)
+	t.Setenv("APP_RESTARTS", "1")
+
+	newStartedApp(t)
+
+	want := "READY=1\nMAINPID=" + strconv.Itoa(os.Getpid())
+	if msg := expectNotify(t, msgs, "READY=1"); msg != want {
+		t.Errorf("notify = %q, want %q", msg, want)
+	}
+}
+
+func TestNotifyWatchdog(t *testing.T) {
+	msgs := listenNotify(t)
+	t.Setenv("WATCHDOG_USEC", "20000")
+
+	a := newStartedApp(t)
+
+	for range 3 {
+		select {
+		case msg := <-msgs:
+			if msg != "WATCHDOG=1" && msg != "READY=1" {
+				t.Fatalf("notify = %q, want watchdog pings", msg)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for watchdog pings")
+		}
+	}
+
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+}
+
+func TestNotifyWatchdogOtherPID(t *testing.T) {
+	msgs := listenNotify(t)
+	t.Setenv("WATCHDOG_USEC", "20000")
+	t.Setenv("WATCHDOG_PID", "1")
+
+	newStartedApp(t)
+	expectNotify(t, msgs, "READY=1")
+
+	select {
+	case msg := <-msgs:
+		t.Errorf("notify = %q, want no watchdog for another PID", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNotifyWithoutSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	a := newStartedApp(t)
+	if err := a.Notify("STATUS=ignored"); err != nil {
+		t.Errorf("Notify() = %v, want no-op", err)
+	}
+}
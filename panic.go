@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+)
+
+// PanicError is a panic recovered from a lifecycle hook, either a handler of
+// OnBoot, OnStart or OnStop or an fx lifecycle hook. Unwrap returns the
+// panic value when it is an error.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("app: panic: %v\n\n%s", e.Value, e.Stack)
+}
+
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// catchPanic runs fn and turns a panic into a PanicError.
+func catchPanic(fn func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn()
+}
+
+// recoveringLifecycle wraps every fx hook in catchPanic. fx runs hooks on a
+// goroutine of its own, out of reach of a recover in Start or Stop, so the
+// hooks are wrapped as they are appended. The wrappers report the hooks
+// under the names of the wrapped functions, and hookLogger drops the events
+// fx emits for the wrappers themselves, which fx attributes to Append.
+type recoveringLifecycle struct {
+	fx.Lifecycle
+	app *BaseApp
+}
+
+func (app *BaseApp) recoverLifecycle(lc fx.Lifecycle) fx.Lifecycle {
+	return &recoveringLifecycle{Lifecycle: lc, app: app}
+}
+
+func (l *recoveringLifecycle) Append(h fx.Hook) {
+	caller := hookCaller()
+
+	if fn := h.OnStart; fn != nil {
+		name := funcName(fn)
+		h.OnStart = func(ctx context.Context) error {
+			return l.app.runFxHook(PhaseStart, name, caller, func() error { return fn(ctx) })
+		}
+	}
+	if fn := h.OnStop; fn != nil {
+		name := funcName(fn)
+		h.OnStop = func(ctx context.Context) error {
+			return l.app.runFxHook(PhaseStop, name, caller, func() error { return fn(ctx) })
+		}
+	}
+
+	l.Lifecycle.Append(h)
+}
+
+// runFxHook runs a wrapped fx hook, reporting it the way fx would.
+func (app *BaseApp) runFxHook(phase, name, caller string, fn func() error) error {
+	logger := &hookLogger{logger: app.fxLogger, app: app}
+
+	if phase == PhaseStart {
+		logger.LogEvent(&fxevent.OnStartExecuting{FunctionName: name, CallerName: caller})
+	} else {
+		logger.LogEvent(&fxevent.OnStopExecuting{FunctionName: name, CallerName: caller})
+	}
+
+	start := time.Now()
+	err := catchPanic(fn)
+	d := time.Since(start)
+
+	if phase == PhaseStart {
+		logger.LogEvent(&fxevent.OnStartExecuted{FunctionName: name, CallerName: caller, Method: "OnStart", Runtime: d, Err: err})
+	} else {
+		logger.LogEvent(&fxevent.OnStopExecuted{FunctionName: name, CallerName: caller, Runtime: d, Err: err})
+	}
+
+	return err
+}
+
+// hookCaller names the function appending a hook, skipping fx's own frames
+// as fx does.
+func hookCaller() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "go.uber.org/fx") || strings.HasSuffix(frame.File, "_test.go") {
+			return frame.Function
+		}
+		if !more {
+			return frame.Function
+		}
+	}
+}
+
+// wrapsHook reports whether fx attributes a hook event to
+// recoveringLifecycle.Append, that is whether the hook is a wrapper.
+func wrapsHook(caller string) bool {
+	return caller == runtime.FuncForPC(reflect.ValueOf((*recoveringLifecycle).Append).Pointer()).Name()
+}
+
+// funcName names fn the way fx names hook functions.
+func funcName(fn any) string {
+	return runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name() + "()"
+}
@@ -0,0 +1,128 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/fx"
+
+	"github.com/rumorsflow/app"
+)
+
+func panickingStart(context.Context) error {
+	panic(errSentinel)
+}
+
+func TestStartHandlerPanic(t *testing.T) {
+	a := newApp(t)
+	a.OnStart().BindFunc(func(*app.StartEvent) error {
+		panic("boom")
+	})
+
+	if err := a.Boot(context.Background()); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+
+	err := a.Start(context.Background())
+	var panicErr *app.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+		t.Fatalf("Start() = %v, want PanicError with a stack", err)
+	}
+	if s := a.State(); s != app.StateBooted {
+		t.Errorf("State() = %v, want %v", s, app.StateBooted)
+	}
+}
+
+func TestFxHookPanic(t *testing.T) {
+	a := newApp(t, fx.Invoke(func(lc fx.Lifecycle) {
+		lc.Append(fx.StartHook(panickingStart))
+	}))
+
+	if err := a.Boot(context.Background()); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+
+	err := a.Start(context.Background())
+	if !errors.As(err, new(*app.PanicError)) || !errors.Is(err, errSentinel) {
+		t.Fatalf("Start() = %v, want PanicError wrapping %v", err, errSentinel)
+	}
+	if code := app.ExitCode(err); code != app.ExitSoftware {
+		t.Errorf("ExitCode() = %d, want %d", code, app.ExitSoftware)
+	}
+
+	timing, ok := findTiming(a.Timings(), app.PhaseStart, "panickingStart")
+	if !ok || !strings.HasSuffix(timing.Caller, "TestFxHookPanic.func1") || timing.Err == "" {
+		t.Errorf("Timings() = %+v, want the hook under its own name and caller with its error", a.Timings())
+	}
+}
+
+func TestStopHandlerPanicStillStops(t *testing.T) {
+	var fxStopped, secondStopped atomic.Bool
+
+	a := newStartedApp(t, fx.Invoke(func(lc fx.Lifecycle) {
+		lc.Append(fx.StopHook(func() { fxStopped.Store(true) }))
+		lc.Append(fx.StopHook(func() { panic("stop hook") }))
+	}))
+	a.OnStop().BindFunc(func(e *app.StopEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+		secondStopped.Store(true)
+		return nil
+	})
+	a.OnStop().BindFunc(func(*app.StopEvent) error {
+		panic("handler")
+	})
+
+	errs := make(chan error, 1)
+	go func() { errs <- a.Stop(context.Background()) }()
+	err := a.Stop(context.Background())
+
+	var panicErr *app.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "handler" {
+		t.Fatalf("Stop() = %v, want the handler's PanicError", err)
+	}
+	if !strings.Contains(err.Error(), "stop hook") {
+		t.Errorf("Stop() = %v, want the fx hook's panic too", err)
+	}
+	if !fxStopped.Load() {
+		t.Error("fx stop hooks did not run after a handler panicked")
+	}
+	if secondStopped.Load() {
+		t.Error("handler after the panicking one ran its Next")
+	}
+	if other := waitErr(t, errs); other != err {
+		t.Errorf("concurrent Stop() = %v, want %v", other, err)
+	}
+	if s := a.State(); s != app.StateStopped {
+		t.Errorf("State() = %v, want %v", s, app.StateStopped)
+	}
+}
+
+func TestRestartPanicExits(t *testing.T) {
+	if os.Getenv("APP_TEST_RESTART_PANIC") == "1" {
+		a := newStartedApp(t)
+		a.OnStop().BindFunc(func(*app.StopEvent) error {
+			panic("restart")
+		})
+		_ = a.Restart(context.Background())
+		t.Fatal("Restart() returned after a panic")
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRestartPanicExits$")
+	cmd.Env = append(os.Environ(), "APP_TEST_RESTART_PANIC=1")
+	out, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != app.ExitSoftware {
+		t.Fatalf("process exited with %v, want code %d:\n%s", err, app.ExitSoftware, out)
+	}
+	if !strings.Contains(string(out), "restart failed") {
+		t.Errorf("output = %s, want the restart error", out)
+	}
+}
@@ -0,0 +1,335 @@
+package app
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ConfigFormat selects the encoding used by PrintConfig.
+type ConfigFormat string
+
+const (
+	FormatJSON ConfigFormat = "json"
+	FormatYAML ConfigFormat = "yaml"
+)
+
+const redactedValue = "******"
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	yamlMarshalerType = reflect.TypeFor[yaml.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// PrintConfig returns a boot hook that loads C like LoadConfig[C] and writes
+// it to w with secrets redacted, before continuing the chain.
+func PrintConfig[C any](w io.Writer, format ConfigFormat) func(*BootEvent) error {
+	return func(event *BootEvent) error {
+		var cfg C
+		if err := event.App.LoadConfig(event.Ctx, &cfg); err != nil {
+			return err
+		}
+
+		if err := writeConfig(w, format, explainOf(event.App), cfg); err != nil {
+			return err
+		}
+
+		return event.Next()
+	}
+}
+
+// ConfigPrinter is implemented by apps that print their effective config,
+// like BaseApp. The admin server serves it on /config.
+type ConfigPrinter interface {
+	PrintConfig(w io.Writer, format ConfigFormat) error
+}
+
+var _ ConfigPrinter = (*BaseApp)(nil)
+
+// PrintConfig writes the running value of every config registered via
+// LoadConfig[C] to w with secrets redacted, one document per config.
+func (app *BaseApp) PrintConfig(w io.Writer, format ConfigFormat) error {
+	return writeConfig(w, format, app.Explain, app.currentConfigs()...)
+}
+
+// explainOf returns the Explain method of a, or nil.
+func explainOf(a App) func(any) Provenance {
+	if e, ok := a.(Explainer); ok {
+		return e.Explain
+	}
+	return nil
+}
+
+// WriteConfig encodes each of cfgs to w as a separate JSON or YAML document.
+// Non-zero fields tagged `secret:"true"`, and fields whose env tag carries
+// the "file" or "unset" option, are replaced with a fixed mask. Values
+// encoding themselves, through a JSON, YAML or text marshaler, are masked
+// as a whole when they hold such fields.
+func WriteConfig(w io.Writer, format ConfigFormat, cfgs ...any) error {
+	return writeConfig(w, format, nil, cfgs...)
+}
+
+// writeConfig is WriteConfig that also masks the fields explain attributes
+// to SourceSecret.
+func writeConfig(w io.Writer, format ConfigFormat, explain func(any) Provenance, cfgs ...any) error {
+	var buf bytes.Buffer
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		for _, cfg := range cfgs {
+			if err := enc.Encode(newRedactor("json", explain, cfg).redact(reflect.ValueOf(cfg), "")); err != nil {
+				return err
+			}
+		}
+	case FormatYAML:
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		for _, cfg := range cfgs {
+			if err := enc.Encode(newRedactor("yaml", explain, cfg).redact(reflect.ValueOf(cfg), "")); err != nil {
+				return err
+			}
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("app: unsupported config format %q", format)
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// isSecretField reports whether the field holds a secret that must never be
+// printed.
+func isSecretField(sf reflect.StructField) bool {
+	if sf.Tag.Get("secret") == "true" {
+		return true
+	}
+	_, opts, _ := strings.Cut(sf.Tag.Get("env"), ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "file" || opt == "unset" {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedField is a key of a redactedObject.
+type redactedField struct {
+	key   string
+	value any
+}
+
+// redactedObject is a struct rendered as an ordered mapping, so printed
+// configs keep the declaration order of their fields.
+type redactedObject []redactedField
+
+func (o redactedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (o redactedObject) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, f := range o {
+		var value yaml.Node
+		if err := value.Encode(f.value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.key}, &value)
+	}
+	return node, nil
+}
+
+// redactor converts configs into values that encode like them under a
+// struct tag, with secret fields masked.
+type redactor struct {
+	tag string
+	// secrets holds the Go paths of fields with resolved secrets.
+	secrets map[string]bool
+}
+
+func newRedactor(tag string, explain func(any) Provenance, cfg any) redactor {
+	r := redactor{tag: tag, secrets: make(map[string]bool)}
+	if explain != nil {
+		for _, o := range explain(cfg) {
+			if o.Source == SourceSecret {
+				r.secrets[o.Field] = true
+			}
+		}
+	}
+	return r
+}
+
+// redact converts v, found at the given Go path.
+func (r redactor) redact(v reflect.Value, field string) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if !v.CanInterface() {
+		return nil
+	}
+	if isOpaque(v.Type()) {
+		// The value's own marshaler would print secrets inside it, so it is
+		// masked as a whole.
+		if !v.IsZero() && (hasSecretFields(v.Type(), nil) || r.hasSecretsBelow(field)) {
+			return redactedValue
+		}
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return r.redactStruct(v, field)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return v.Interface()
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = r.redact(v.Index(i), field)
+		}
+		return items
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+
+		obj := make(redactedObject, 0, len(keys))
+		for _, key := range keys {
+			obj = append(obj, redactedField{key: key.String(), value: r.redact(v.MapIndex(key), field)})
+		}
+		return obj
+	default:
+		return v.Interface()
+	}
+}
+
+func (r redactor) redactStruct(v reflect.Value, parent string) redactedObject {
+	obj := make(redactedObject, 0, v.NumField())
+	for i := range v.NumField() {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		field := parent
+		if !sf.Anonymous {
+			if field != "" {
+				field += "."
+			}
+			field += sf.Name
+		}
+
+		name, opts, _ := strings.Cut(sf.Tag.Get(r.tag), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		fv := v.Field(i)
+		inline := sf.Anonymous && name == "" || slices.Contains(strings.Split(opts, ","), "inline")
+		if inline {
+			if nested, ok := r.redact(fv, field).(redactedObject); ok {
+				obj = append(obj, nested...)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = sf.Name
+			if r.tag == "yaml" {
+				name = strings.ToLower(name)
+			}
+		}
+
+		value := r.redact(fv, field)
+		if (isSecretField(sf) || r.secrets[field]) && !fv.IsZero() {
+			value = redactedValue
+		}
+		obj = append(obj, redactedField{key: name, value: value})
+	}
+	return obj
+}
+
+// hasSecretsBelow reports whether a resolved secret is at the Go path field
+// or within it.
+func (r redactor) hasSecretsBelow(field string) bool {
+	for secret := range r.secrets {
+		if field == "" || secret == field || strings.HasPrefix(secret, field+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSecretFields reports whether values of t hold fields marked secret.
+// seen guards against recursive types.
+func hasSecretFields(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return false
+	}
+	if seen == nil {
+		seen = make(map[reflect.Type]bool)
+	}
+	seen[t] = true
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if isSecretField(sf) || hasSecretFields(sf.Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOpaque reports whether values of t encode themselves and must be passed
+// to the encoder as is.
+func isOpaque(t reflect.Type) bool {
+	for _, m := range []reflect.Type{jsonMarshalerType, yamlMarshalerType, textMarshalerType} {
+		if t.Implements(m) || reflect.PointerTo(t).Implements(m) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rumorsflow/app"
+)
+
+type secretConfig struct {
+	DB struct {
+		User     string `json:"user" yaml:"user"`
+		Password string `json:"password" yaml:"password" secret:"true"`
+	} `json:"db" yaml:"db"`
+	Token   string        `json:"token" env:"TOKEN,unset"`
+	Empty   string        `json:"empty" secret:"true"`
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+func TestWriteConfig(t *testing.T) {
+	var cfg secretConfig
+	cfg.DB.User = "admin"
+	cfg.DB.Password = "hunter2"
+	cfg.Token = "tok"
+	cfg.Timeout = 5 * time.Second
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := app.WriteConfig(&buf, app.FormatJSON, cfg); err != nil {
+			t.Fatalf("WriteConfig() = %v", err)
+		}
+
+		want := `{
+  "db": {
+    "user": "admin",
+    "password": "******"
+  },
+  "token": "******",
+  "empty": "",
+  "timeout": 5000000000
+}
+`
+		if got := buf.String(); got != want {
+			t.Errorf("WriteConfig() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := app.WriteConfig(&buf, app.FormatYAML, &cfg); err != nil {
+			t.Fatalf("WriteConfig() = %v", err)
+		}
+
+		want := `db:
+  user: admin
+  password: '******'
+token: '******'
+empty: ""
+timeout: 5s
+`
+		if got := buf.String(); got != want {
+			t.Errorf("WriteConfig() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		if err := app.WriteConfig(&bytes.Buffer{}, "xml", cfg); err == nil {
+			t.Error("WriteConfig() = nil, want unsupported format error")
+		}
+	})
+}
+
+// dsnConfig encodes itself, secret field included.
+type dsnConfig struct {
+	Host     string
+	Password string `secret:"true"`
+}
+
+func (c dsnConfig) MarshalText() ([]byte, error) {
+	return []byte("postgres://app:" + c.Password + "@" + c.Host), nil
+}
+
+func TestWriteConfigOpaqueSecrets(t *testing.T) {
+	cfg := struct {
+		DSN     dsnConfig `json:"dsn"`
+		Backup  dsnConfig `json:"backup"`
+		Started time.Time `json:"started"`
+	}{
+		DSN:     dsnConfig{Host: "db", Password: "hunter2"},
+		Started: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	if err := app.WriteConfig(&buf, app.FormatJSON, cfg); err != nil {
+		t.Fatalf("WriteConfig() = %v", err)
+	}
+
+	want := `{
+  "dsn": "******",
+  "backup": "postgres://app:@",
+  "started": "2026-01-01T00:00:00Z"
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteConfig() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	a := configApp(app.Config{
+		StartTimeout: 10 * time.Second,
+		StopTimeout:  10 * time.Second,
+		ConfigRaw:    []byte(`{"addr":"cfg","port":8080,"db":{"password":"hunter2"}}`),
+	})
+	a.OnBoot().BindFunc(app.LoadConfig[netConfig]())
+	a.OnBoot().BindFunc(app.LoadConfig[secretConfig]())
+
+	var boot bytes.Buffer
+	a.OnBoot().BindFunc(app.PrintConfig[netConfig](&boot, app.FormatJSON))
+
+	if err := a.Boot(context.Background()); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+	if !strings.Contains(boot.String(), `"addr": "cfg"`) {
+		t.Errorf("boot hook output = %s, want addr", boot.String())
+	}
+
+	var buf bytes.Buffer
+	if err := a.PrintConfig(&buf, app.FormatYAML); err != nil {
+		t.Fatalf("PrintConfig() = %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "addr: cfg") || !strings.Contains(out, "\n---\n") {
+		t.Errorf("PrintConfig() = %s, want both configs as separate documents", out)
+	}
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("PrintConfig() = %s, leaks secret", out)
+	}
+}
@@ -0,0 +1,327 @@
+package app
+
+import (
+	"encoding"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/caarlos0/env/v11"
+)
+
+// Source identifies the layer of LoadConfig that set a config field.
+type Source string
+
+const (
+	SourceRaw        Source = "raw"
+	SourceFile       Source = "file"
+	SourceEnv        Source = "env"
+	SourceEnvDefault Source = "env-default"
+	SourceDefaults   Source = "defaults"
+	SourceSecret     Source = "secret"
+)
+
+// Origin tells where the value of a single config field came from.
+type Origin struct {
+	// Field is the Go path of the field, e.g. "HTTP.Timeout".
+	Field  string
+	Source Source
+	// File is the config file path for SourceFile.
+	File string
+	// Key is the key within File, the environment variable name for
+	// SourceEnv and SourceEnvDefault, or the reference for SourceSecret.
+	Key string
+}
+
+func (o Origin) String() string {
+	switch {
+	case o.File != "" && o.Key != "":
+		return fmt.Sprintf("%s: %s %s (%s)", o.Field, o.Source, o.File, o.Key)
+	case o.File != "":
+		return fmt.Sprintf("%s: %s %s", o.Field, o.Source, o.File)
+	case o.Key != "":
+		return fmt.Sprintf("%s: %s %s", o.Field, o.Source, o.Key)
+	default:
+		return fmt.Sprintf("%s: %s", o.Field, o.Source)
+	}
+}
+
+// Provenance lists the origin of every field set while loading a config,
+// sorted by field. Fields left at their zero value are absent.
+type Provenance []Origin
+
+// Lookup returns the origin of the field at the given Go path.
+func (p Provenance) Lookup(field string) (Origin, bool) {
+	i, ok := slices.BinarySearchFunc(p, field, func(o Origin, field string) int {
+		return strings.Compare(o.Field, field)
+	})
+	if !ok {
+		return Origin{}, false
+	}
+	return p[i], true
+}
+
+// Explainer is implemented by apps that trace where config values came from,
+// like BaseApp. Printed configs mask the values it attributes to secrets.
+type Explainer interface {
+	Explain(cfg any) Provenance
+}
+
+var _ Explainer = (*BaseApp)(nil)
+
+// Explain returns the provenance recorded by the last successful LoadConfig
+// of cfg's type. cfg may be a value or a pointer.
+func (app *BaseApp) Explain(cfg any) Provenance {
+	t := reflect.TypeOf(cfg)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	app.provenanceMu.RLock()
+	defer app.provenanceMu.RUnlock()
+
+	return app.provenance[t]
+}
+
+func (app *BaseApp) setProvenance(out any, p Provenance) {
+	app.provenanceMu.Lock()
+	defer app.provenanceMu.Unlock()
+
+	if app.provenance == nil {
+		app.provenance = make(map[reflect.Type]Provenance)
+	}
+	app.provenance[reflect.TypeOf(out).Elem()] = p
+}
+
+// fileKeyTags maps config file extensions to the struct tag their decoder
+// names keys by.
+var fileKeyTags = map[string]string{
+	".json": "json",
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".toml": "toml",
+	".ini":  "ini",
+}
+
+type configLeaf struct {
+	field  string
+	path   []reflect.StructField
+	envKey string
+	value  string
+}
+
+// key returns the name the leaf has in a document whose decoder reads the
+// given struct tag.
+func (l configLeaf) key(tag string) string {
+	parts := make([]string, 0, len(l.path))
+	for _, f := range l.path {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" || name == "" {
+			if f.Anonymous {
+				continue
+			}
+			name = f.Name
+			if tag == "yaml" {
+				name = strings.ToLower(name)
+			}
+		}
+		parts = append(parts, name)
+	}
+	return strings.Join(parts, ".")
+}
+
+// configTrace attributes changes to the fields of a config struct to the
+// LoadConfig layer that made them, by comparing snapshots taken around
+// every layer.
+type configTrace struct {
+	out     any
+	opts    env.Options
+	leaves  map[string]configLeaf
+	origins map[string]Origin
+}
+
+func newConfigTrace(out any, opts env.Options) *configTrace {
+	if opts.TagName == "" {
+		opts.TagName = "env"
+	}
+	if opts.PrefixTagName == "" {
+		opts.PrefixTagName = "envPrefix"
+	}
+	if opts.DefaultValueTagName == "" {
+		opts.DefaultValueTagName = "envDefault"
+	}
+
+	t := &configTrace{out: out, opts: opts, origins: make(map[string]Origin)}
+	t.leaves = t.snapshot()
+	return t
+}
+
+// record attributes every field changed since the last snapshot.
+func (t *configTrace) record(origin func(configLeaf) Origin) {
+	leaves := t.snapshot()
+	for field, leaf := range leaves {
+		if prev, ok := t.leaves[field]; ok && prev.value == leaf.value {
+			continue
+		}
+		o := origin(leaf)
+		o.Field = field
+		t.origins[field] = o
+	}
+	t.leaves = leaves
+}
+
+func (t *configTrace) raw() {
+	t.record(func(configLeaf) Origin { return Origin{Source: SourceRaw} })
+}
+
+func (t *configTrace) file(name string) {
+	ext := strings.ToLower(filepath.Ext(name))
+	t.record(func(l configLeaf) Origin {
+		o := Origin{Source: SourceFile, File: name}
+		if ext == ".env" {
+			o.Key = l.envKey
+		} else if tag, ok := fileKeyTags[ext]; ok {
+			o.Key = l.key(tag)
+		}
+		return o
+	})
+}
+
+func (t *configTrace) defaults() {
+	t.record(func(configLeaf) Origin { return Origin{Source: SourceDefaults} })
+}
+
+// env attributes the environment layer. A variable that is present wins
+// even when it repeats the value an earlier layer set.
+func (t *configTrace) env() {
+	lookup := lookupEnv(t.opts)
+
+	t.record(func(l configLeaf) Origin {
+		if _, ok := lookup(l.envKey); ok {
+			return Origin{Source: SourceEnv, Key: l.envKey}
+		}
+		return Origin{Source: SourceEnvDefault, Key: l.envKey}
+	})
+
+	for field, leaf := range t.leaves {
+		if leaf.envKey == "" {
+			continue
+		}
+		if _, ok := lookup(leaf.envKey); ok {
+			t.origins[field] = Origin{Field: field, Source: SourceEnv, Key: leaf.envKey}
+		}
+	}
+}
+
+// secrets attributes the fields holding resolved secret references.
+func (t *configTrace) secrets(refs map[string]string) {
+	for field, ref := range refs {
+		t.origins[field] = Origin{Field: field, Source: SourceSecret, Key: ref}
+	}
+	t.leaves = t.snapshot()
+}
+
+func (t *configTrace) result() Provenance {
+	p := make(Provenance, 0, len(t.origins))
+	for _, o := range t.origins {
+		p = append(p, o)
+	}
+	slices.SortFunc(p, func(a, b Origin) int { return strings.Compare(a.Field, b.Field) })
+	return p
+}
+
+func (t *configTrace) snapshot() map[string]configLeaf {
+	leaves := make(map[string]configLeaf)
+	t.walk(reflect.ValueOf(t.out), "", t.opts.Prefix, nil, leaves)
+	return leaves
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+func (t *configTrace) walk(v reflect.Value, field, prefix string, path []reflect.StructField, leaves map[string]configLeaf) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if len(path) > 0 {
+				t.leaf(v, field, prefix, path, leaves)
+			}
+			return
+		}
+		v = v.Elem()
+	}
+
+	if len(path) > 0 && !isConfigStruct(v) {
+		t.leaf(v, field, prefix, path, leaves)
+		return
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := range v.NumField() {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := field
+		if !sf.Anonymous {
+			if name != "" {
+				name += "."
+			}
+			name += sf.Name
+		}
+
+		fieldPrefix := prefix + sf.Tag.Get(t.opts.PrefixTagName)
+		t.walk(v.Field(i), name, fieldPrefix, append(slices.Clip(path), sf), leaves)
+	}
+}
+
+func (t *configTrace) leaf(v reflect.Value, field, prefix string, path []reflect.StructField, leaves map[string]configLeaf) {
+	sf := path[len(path)-1]
+
+	ownKey, _, _ := strings.Cut(sf.Tag.Get(t.opts.TagName), ",")
+	if ownKey == "" && t.opts.UseFieldNameByDefault {
+		ownKey = toEnvName(sf.Name)
+	}
+
+	leaf := configLeaf{field: field, path: path, value: fmt.Sprintf("%#v", v.Interface())}
+	if ownKey != "" && ownKey != "-" {
+		leaf.envKey = prefix + ownKey
+	}
+	leaves[field] = leaf
+}
+
+// isConfigStruct reports whether v is a struct LoadConfig layers descend
+// into rather than a single value such as time.Time.
+func isConfigStruct(v reflect.Value) bool {
+	if v.Kind() != reflect.Struct || reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return false
+	}
+	for i := range v.NumField() {
+		if v.Type().Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// toEnvName mirrors the field name conversion env applies with
+// UseFieldNameByDefault.
+func toEnvName(input string) string {
+	var output []rune
+	for i, c := range input {
+		if c == '_' {
+			continue
+		}
+		if len(output) > 0 && unicode.IsUpper(c) && len(input) > i+1 {
+			if unicode.IsLower(rune(input[i+1])) || unicode.IsLower(rune(input[i-1])) {
+				output = append(output, '_')
+			}
+		}
+		output = append(output, unicode.ToUpper(c))
+	}
+	return string(output)
+}
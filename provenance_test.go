@@ -0,0 +1,87 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rumorsflow/app"
+)
+
+type provenanceConfig struct {
+	HTTP struct {
+		Addr    string        `json:"addr" yaml:"addr" env:"ADDR"`
+		Timeout time.Duration `json:"timeout" yaml:"timeout" env:"TIMEOUT"`
+	} `json:"http" yaml:"http" envPrefix:"HTTP_"`
+	Name  string `json:"name" env:"NAME"`
+	Level string `env:"LEVEL" envDefault:"info"`
+	Mode  string
+}
+
+func (c *provenanceConfig) SetDefaults() {
+	if c.Mode == "" {
+		c.Mode = "standalone"
+	}
+}
+
+func TestExplain(t *testing.T) {
+	t.Setenv("TESTAPP_HTTP_TIMEOUT", "5s")
+	t.Setenv("TESTAPP_NAME", "raw")
+
+	path := writeConfigFile(t, "config.yaml", "http:\n  addr: ':8080'\n  timeout: 1s\n")
+	a := configApp(app.Config{
+		ConfigRaw:   []byte(`{"name":"raw","http":{"addr":":80"}}`),
+		ConfigFiles: []string{path},
+		EnvPrefix:   "TESTAPP_",
+	})
+
+	var cfg provenanceConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+
+	want := map[string]app.Origin{
+		"HTTP.Addr":    {Field: "HTTP.Addr", Source: app.SourceFile, File: path, Key: "http.addr"},
+		"HTTP.Timeout": {Field: "HTTP.Timeout", Source: app.SourceEnv, Key: "TESTAPP_HTTP_TIMEOUT"},
+		// The variable repeats the raw value but still takes precedence.
+		"Name":  {Field: "Name", Source: app.SourceEnv, Key: "TESTAPP_NAME"},
+		"Level": {Field: "Level", Source: app.SourceEnvDefault, Key: "TESTAPP_LEVEL"},
+		"Mode":  {Field: "Mode", Source: app.SourceDefaults},
+	}
+
+	p := a.Explain(cfg)
+	if len(p) != len(want) {
+		t.Errorf("Explain() = %v, want %d origins", p, len(want))
+	}
+	for field, w := range want {
+		got, ok := p.Lookup(field)
+		if !ok {
+			t.Errorf("Lookup(%q) not found", field)
+			continue
+		}
+		if got != w {
+			t.Errorf("Lookup(%q) = %+v, want %+v", field, got, w)
+		}
+	}
+
+	if got := a.Explain(&cfg); len(got) != len(p) {
+		t.Errorf("Explain(&cfg) = %v, want same as Explain(cfg)", got)
+	}
+}
+
+func TestExplainRawSource(t *testing.T) {
+	a := configApp(app.Config{ConfigRaw: []byte(`{"addr":"raw"}`)})
+
+	var cfg netConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+
+	got, ok := a.Explain(cfg).Lookup("Addr")
+	if !ok || got.Source != app.SourceRaw {
+		t.Errorf("Lookup(Addr) = %+v, %v, want raw source", got, ok)
+	}
+	if _, ok := a.Explain(cfg).Lookup("Port"); ok {
+		t.Error("Lookup(Port) found an origin for a field no layer set")
+	}
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/gowool/hook"
+)
+
+// ConfigChange pairs the running value of a config registered via
+// LoadConfig[C] with its freshly loaded replacement.
+type ConfigChange struct {
+	Old any
+	New any
+
+	entry *configEntry
+}
+
+type ReloadEvent struct {
+	hook.Event
+	App     App
+	Ctx     context.Context
+	Changes []ConfigChange
+	// Err is the load or validation failure, if any. The running config is
+	// kept and Reload returns Err once the chain completes.
+	Err error
+}
+
+// Reloader is implemented by apps that reload their configs, like BaseApp.
+// Components that receive the app as App assert to it to reload on demand
+// or to subscribe to OnReload.
+type Reloader interface {
+	Reload(ctx context.Context) error
+	OnReload() *hook.Hook[*ReloadEvent]
+}
+
+var _ Reloader = (*BaseApp)(nil)
+
+type configEntry struct {
+	typ   reflect.Type
+	value any
+	load  func(ctx context.Context) (any, error)
+}
+
+type configRegistrar interface {
+	registerConfig(entry *configEntry)
+}
+
+// ReloadConfig returns an OnReload handler that delivers the old and new
+// values of config C to fn after a successful reload.
+func ReloadConfig[C any](fn func(ctx context.Context, old, new C) error) func(*ReloadEvent) error {
+	return func(event *ReloadEvent) error {
+		if event.Err == nil {
+			for _, change := range event.Changes {
+				old, ok := change.Old.(C)
+				if !ok {
+					continue
+				}
+				if err := fn(event.Ctx, old, change.New.(C)); err != nil {
+					return err
+				}
+			}
+		}
+
+		return event.Next()
+	}
+}
+
+func (app *BaseApp) registerConfig(entry *configEntry) {
+	app.configMu.Lock()
+	defer app.configMu.Unlock()
+
+	// Booting after Verify runs LoadConfig[C] twice; keep one entry per type.
+	for i, existing := range app.configs {
+		if existing.typ == entry.typ {
+			app.configs[i] = entry
+			return
+		}
+	}
+	app.configs = append(app.configs, entry)
+}
+
+// currentConfigs returns the running values of the configs registered via
+// LoadConfig[C], in registration order.
+func (app *BaseApp) currentConfigs() []any {
+	app.configMu.RLock()
+	defer app.configMu.RUnlock()
+
+	values := make([]any, len(app.configs))
+	for i, entry := range app.configs {
+		values[i] = entry.value
+	}
+	return values
+}
+
+func (app *BaseApp) OnReload() *hook.Hook[*ReloadEvent] {
+	return app.onReload
+}
+
+// Reload loads and validates fresh values of every config registered via
+// LoadConfig[C] and triggers OnReload with the changes. The new values
+// become current only once the whole chain succeeds.
+func (app *BaseApp) Reload(ctx context.Context) error {
+	app.reloadMu.Lock()
+	defer app.reloadMu.Unlock()
+
+	app.configMu.RLock()
+	entries := slices.Clone(app.configs)
+	app.configMu.RUnlock()
+
+	if app.State() == StateRunning {
+		app.notifier.reloading()
+		defer app.notifier.ready(false)
+	}
+
+	event := &ReloadEvent{App: app, Ctx: ctx, Changes: make([]ConfigChange, 0, len(entries))}
+
+	for _, entry := range entries {
+		value, err := entry.load(ctx)
+		if err != nil {
+			event.Err = fmt.Errorf("app: unable to reload config: %w", err)
+			event.Changes = nil
+			break
+		}
+		event.Changes = append(event.Changes, ConfigChange{Old: entry.value, New: value, entry: entry})
+	}
+
+	return app.OnReload().Trigger(event, app.reload)
+}
+
+func (app *BaseApp) reload(event *ReloadEvent) error {
+	if event.Err != nil {
+		return event.Err
+	}
+
+	app.configMu.Lock()
+	for _, change := range event.Changes {
+		if change.entry != nil {
+			change.entry.value = change.New
+		}
+	}
+	app.configMu.Unlock()
+
+	return event.Next()
+}
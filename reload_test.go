@@ -0,0 +1,191 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/rumorsflow/app"
+)
+
+func reloadApp(t *testing.T, path string) *app.BaseApp {
+	t.Helper()
+
+	a := configApp(app.Config{
+		StartTimeout: 10 * time.Second,
+		StopTimeout:  10 * time.Second,
+		ConfigFiles:  []string{path},
+	})
+	a.OnBoot().BindFunc(app.LoadConfig[netConfig]())
+	a.OnBoot().BindFunc(app.LoadConfig[validatedConfig]())
+	return a
+}
+
+func TestReloadDeliversChanges(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{"addr":"one","port":1}`)
+	a := reloadApp(t, path)
+
+	var old, cur netConfig
+	calls := 0
+	a.OnReload().BindFunc(app.ReloadConfig(func(_ context.Context, o, n netConfig) error {
+		calls++
+		old, cur = o, n
+		return nil
+	}))
+
+	ctx := context.Background()
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"addr":"two","port":2}`), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	if err := a.Reload(ctx); err != nil {
+		t.Fatalf("Reload() = %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("subscriber called %d times, want 1", calls)
+	}
+	if old.Addr != "one" || cur.Addr != "two" {
+		t.Errorf("old = %+v, new = %+v, want one -> two", old, cur)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"addr":"three","port":3}`), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	if err := a.Reload(ctx); err != nil {
+		t.Fatalf("second Reload() = %v", err)
+	}
+	if old.Addr != "two" || cur.Addr != "three" {
+		t.Errorf("old = %+v, new = %+v, want two -> three", old, cur)
+	}
+}
+
+func TestReloadValidationFailureKeepsConfig(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{"addr":"one"}`)
+	a := reloadApp(t, path)
+
+	var old netConfig
+	var hookErr error
+	a.OnReload().BindFunc(func(e *app.ReloadEvent) error {
+		hookErr = e.Err
+		return e.Next()
+	})
+	a.OnReload().BindFunc(app.ReloadConfig(func(_ context.Context, o, _ netConfig) error {
+		old = o
+		return nil
+	}))
+
+	ctx := context.Background()
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"addr":"bad","fail":true}`), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	if err := a.Reload(ctx); !errors.Is(err, errSentinel) {
+		t.Fatalf("Reload() = %v, want %v", err, errSentinel)
+	}
+	if !errors.Is(hookErr, errSentinel) {
+		t.Errorf("reload event Err = %v, want %v", hookErr, errSentinel)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"addr":"good"}`), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	if err := a.Reload(ctx); err != nil {
+		t.Fatalf("Reload() = %v", err)
+	}
+	if old.Addr != "one" {
+		t.Errorf("old.Addr = %q, want %q: failed reload must not replace the running config", old.Addr, "one")
+	}
+}
+
+func TestReloaderFromInjectedApp(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{"addr":"one"}`)
+	a := reloadApp(t, path)
+
+	var injected app.App
+	a.OnBoot().BindFunc(app.Options(fx.Invoke(func(a app.App) { injected = a })))
+
+	ctx := context.Background()
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+
+	r, ok := injected.(app.Reloader)
+	if !ok {
+		t.Fatalf("%T does not implement app.Reloader", injected)
+	}
+
+	var cur netConfig
+	r.OnReload().BindFunc(app.ReloadConfig(func(_ context.Context, _, n netConfig) error {
+		cur = n
+		return nil
+	}))
+
+	if err := os.WriteFile(path, []byte(`{"addr":"two"}`), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	if err := r.Reload(ctx); err != nil {
+		t.Fatalf("Reload() = %v", err)
+	}
+	if cur.Addr != "two" {
+		t.Errorf("cur.Addr = %q, want %q", cur.Addr, "two")
+	}
+}
+
+func TestRunReloadsOnSIGHUP(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("reload signal is not supported on windows")
+	}
+
+	// Same rationale as the SIGTERM safety net in app_test.go.
+	safety := make(chan os.Signal, 1)
+	signal.Notify(safety, syscall.SIGHUP)
+
+	a := reloadApp(t, writeConfigFile(t, "config.json", `{"addr":"one"}`))
+
+	reloaded := make(chan struct{})
+	a.OnReload().BindFunc(func(e *app.ReloadEvent) error {
+		select {
+		case <-reloaded:
+		default:
+			close(reloaded)
+		}
+		return e.Next()
+	})
+
+	runErr, started := startRun(t, a)
+	waitClosed(t, started, "app did not start")
+
+	deadline := time.After(10 * time.Second)
+	for done := false; !done; {
+		select {
+		case <-reloaded:
+			done = true
+		case <-deadline:
+			t.Fatal("app did not reload after SIGHUP")
+		case <-time.After(50 * time.Millisecond):
+			if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+				t.Fatalf("kill: %v", err)
+			}
+		}
+	}
+
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if err := waitErr(t, runErr); err != nil {
+		t.Errorf("Run() = %v, want nil", err)
+	}
+}
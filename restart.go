@@ -1,7 +1,30 @@
 package app
 
-import "syscall"
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"syscall"
+)
 
+// restartListeners counts the Run calls in this process serving signals with
+// SIGUSR1 mapped to SignalRestart.
+var restartListeners atomic.Int32
+
+// Restart asks a running app to restart by sending SIGUSR1 to the own
+// process. It fails with ErrRestartUnsupported unless Run is serving signals
+// with SIGUSR1 mapped to SignalRestart, as it is by default: an unmapped
+// SIGUSR1 terminates the process and any other action would run instead.
 func Restart() error {
+	if restartListeners.Load() == 0 {
+		return fmt.Errorf("%w: SIGUSR1 is not mapped to SignalRestart by a running app", ErrRestartUnsupported)
+	}
 	return syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
 }
+
+// restartsOn reports whether sig is mapped to SignalRestart.
+func restartsOn(signals map[os.Signal]SignalAction, sig os.Signal) bool {
+	action, ok := signals[sig]
+	return ok && reflect.ValueOf(action).Pointer() == reflect.ValueOf(SignalRestart).Pointer()
+}
@@ -0,0 +1,196 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/fx/fxevent"
+)
+
+// Clock is the time source of the Scheduler. Tests inject a fake through
+// Config.Clock to fire jobs without waiting.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Schedule yields the next activation after a given time.
+type Schedule interface {
+	Next(time.Time) time.Time
+}
+
+// Cron parses a standard five field cron expression or a descriptor such as
+// "@hourly" or "@every 5m".
+func Cron(spec string) (Schedule, error) {
+	s, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("app: invalid cron expression %q: %w", spec, err)
+	}
+	return s, nil
+}
+
+// Every schedules a job at a fixed interval, the first run one interval
+// after the scheduler started.
+func Every(d time.Duration) Schedule {
+	return every(d)
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// OverlapPolicy decides what happens when a job is due while its previous
+// run is still in flight.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops the new run.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue starts the new run once the previous one finished.
+	OverlapQueue
+	// OverlapAllow runs both concurrently.
+	OverlapAllow
+)
+
+// ErrJobSkipped is reported for a run dropped by OverlapSkip.
+var ErrJobSkipped = errors.New("app: job skipped, previous run still in flight")
+
+// Job is a task run by the Scheduler.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+	// Overlap is OverlapSkip when zero.
+	Overlap OverlapPolicy
+	// Jitter delays every run by a random duration up to Jitter.
+	Jitter time.Duration
+	// Timeout bounds a single run; unbounded when zero.
+	Timeout time.Duration
+}
+
+// Scheduler runs jobs on their schedules while the app runs. Jobs start
+// ticking once Start succeeded and, like workers, are stopped in the
+// StopWorkers phase, which waits for runs in flight. Every run is reported
+// to the fx logger as an fxevent.Run of kind "job", a panicking run with a
+// PanicError. It is supplied to the fx graph by Boot:
+//
+//	fx.Invoke(func(s *app.Scheduler, c *Cleaner) error {
+//		schedule, err := app.Cron("0 3 * * *")
+//		if err != nil {
+//			return err
+//		}
+//		return s.Add(app.Job{Name: "cleanup", Schedule: schedule, Run: c.Run})
+//	})
+type Scheduler struct {
+	workers *Workers
+	clock   Clock
+	logger  func() fxevent.Logger
+}
+
+func newScheduler(workers *Workers, clock Clock, logger func() fxevent.Logger) *Scheduler {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &Scheduler{workers: workers, clock: clock, logger: logger}
+}
+
+// Add registers a job.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil {
+		return errors.New("app: job needs a name, a schedule and a func")
+	}
+	return s.workers.Go(Worker{Name: "job " + job.Name, Run: s.loop(job)})
+}
+
+func (s *Scheduler) loop(job Job) func(context.Context) error {
+	return func(ctx context.Context) error {
+		var (
+			wg       sync.WaitGroup
+			queue    sync.Mutex
+			inFlight = make(chan struct{}, 1)
+		)
+		defer wg.Wait()
+
+		next := s.clock.Now()
+		for {
+			next = job.Schedule.Next(next)
+			if next.IsZero() {
+				return nil
+			}
+
+			delay := next.Sub(s.clock.Now())
+			if job.Jitter > 0 {
+				delay += rand.N(job.Jitter)
+			}
+
+			select {
+			case <-s.clock.After(delay):
+			case <-ctx.Done():
+				return nil
+			}
+
+			switch job.Overlap {
+			case OverlapSkip:
+				select {
+				case inFlight <- struct{}{}:
+					wg.Go(func() {
+						defer func() { <-inFlight }()
+						s.run(ctx, job)
+					})
+				default:
+					s.report(job, 0, ErrJobSkipped)
+				}
+			case OverlapQueue:
+				wg.Go(func() {
+					queue.Lock()
+					defer queue.Unlock()
+
+					if ctx.Err() == nil {
+						s.run(ctx, job)
+					}
+				})
+			default:
+				wg.Go(func() { s.run(ctx, job) })
+			}
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	start := s.clock.Now()
+	err := catchPanic(func() error { return job.Run(ctx) })
+	s.report(job, s.clock.Now().Sub(start), err)
+}
+
+func (s *Scheduler) report(job Job, d time.Duration, err error) {
+	if logger := s.logger(); logger != nil {
+		logger.LogEvent(&fxevent.Run{Name: job.Name, Kind: "job", Runtime: d, Err: err})
+	}
+}
+
+func (app *BaseApp) Scheduler() *Scheduler {
+	return app.scheduler
+}
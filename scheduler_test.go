@@ -0,0 +1,325 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+
+	"github.com/rumorsflow/app"
+)
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance waits for n pending timers, then moves the clock and fires the
+// timers that are due.
+func (c *fakeClock) Advance(t *testing.T, n int, d time.Duration) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		c.mu.Lock()
+		pending := len(c.waiters)
+		c.mu.Unlock()
+		if pending >= n {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d timers, have %d", n, pending)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	kept := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			kept = append(kept, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = kept
+}
+
+type jobLogger struct {
+	mu   sync.Mutex
+	runs []*fxevent.Run
+}
+
+func (l *jobLogger) LogEvent(event fxevent.Event) {
+	if e, ok := event.(*fxevent.Run); ok && e.Kind == "job" {
+		l.mu.Lock()
+		l.runs = append(l.runs, e)
+		l.mu.Unlock()
+	}
+}
+
+func (l *jobLogger) errors() []error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	errs := make([]error, len(l.runs))
+	for i, r := range l.runs {
+		errs[i] = r.Err
+	}
+	return errs
+}
+
+func schedulerApp(t *testing.T, clock app.Clock, jobs ...app.Job) (*app.BaseApp, *jobLogger) {
+	t.Helper()
+
+	logger := &jobLogger{}
+	a := app.NewBaseApp(app.Config{
+		StartTimeout: 10 * time.Second,
+		StopTimeout:  10 * time.Second,
+		Clock:        clock,
+	})
+	a.OnBoot().BindFunc(func(e *app.BootEvent) error {
+		e.Logger = logger
+		e.Options = append(e.Options, fx.Invoke(func(s *app.Scheduler) error {
+			for _, job := range jobs {
+				if err := s.Add(job); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+		return e.Next()
+	})
+
+	ctx := context.Background()
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	t.Cleanup(func() { _ = a.Stop(context.Background()) })
+
+	return a, logger
+}
+
+func TestSchedulerEvery(t *testing.T) {
+	clock := newFakeClock()
+	runs := make(chan struct{}, 10)
+	var n atomic.Int32
+
+	_, logger := schedulerApp(t, clock, app.Job{
+		Name:     "tick",
+		Schedule: app.Every(time.Minute),
+		Run: func(context.Context) error {
+			runs <- struct{}{}
+			if n.Add(1) == 2 {
+				return errSentinel
+			}
+			return nil
+		},
+	})
+
+	for i := range 2 {
+		clock.Advance(t, 1, time.Minute)
+		select {
+		case <-runs:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("run %d did not happen", i+1)
+		}
+	}
+
+	errs := logger.errors()
+	for deadline := time.Now().Add(10 * time.Second); len(errs) < 2 && time.Now().Before(deadline); errs = logger.errors() {
+		time.Sleep(time.Millisecond)
+	}
+	if len(errs) != 2 || errs[0] != nil || !errors.Is(errs[1], errSentinel) {
+		t.Fatalf("reported runs = %v, want a success and a failure", errs)
+	}
+}
+
+func TestSchedulerPanic(t *testing.T) {
+	clock := newFakeClock()
+	runs := make(chan struct{}, 10)
+
+	_, logger := schedulerApp(t, clock, app.Job{
+		Name:     "panicking",
+		Schedule: app.Every(time.Minute),
+		Run: func(context.Context) error {
+			runs <- struct{}{}
+			panic(errSentinel)
+		},
+		Overlap: app.OverlapAllow,
+	})
+
+	for i := range 2 {
+		clock.Advance(t, 1, time.Minute)
+		select {
+		case <-runs:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("run %d did not happen", i+1)
+		}
+	}
+
+	for deadline := time.Now().Add(10 * time.Second); len(logger.errors()) < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.runs) != 2 {
+		t.Fatalf("reported %d runs, want 2", len(logger.runs))
+	}
+	for _, run := range logger.runs {
+		if !errors.As(run.Err, new(*app.PanicError)) || !errors.Is(run.Err, errSentinel) {
+			t.Errorf("reported run = %v, want the panic", run.Err)
+		}
+		if run.Runtime != 0 {
+			t.Errorf("reported runtime = %v, want it measured by the fake clock", run.Runtime)
+		}
+	}
+}
+
+func TestSchedulerOverlap(t *testing.T) {
+	tests := []struct {
+		overlap     app.OverlapPolicy
+		wantRuns    int32
+		wantMaxRuns int32
+		wantSkipped bool
+	}{
+		{app.OverlapSkip, 1, 1, true},
+		{app.OverlapQueue, 2, 1, false},
+		{app.OverlapAllow, 2, 2, false},
+	}
+	for _, tt := range tests {
+		clock := newFakeClock()
+		release := make(chan struct{})
+		var runs, running, maxRunning atomic.Int32
+
+		a, logger := schedulerApp(t, clock, app.Job{
+			Name:     "slow",
+			Schedule: app.Every(time.Minute),
+			Overlap:  tt.overlap,
+			Run: func(context.Context) error {
+				runs.Add(1)
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					m := maxRunning.Load()
+					if n <= m || maxRunning.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				<-release
+				return nil
+			},
+		})
+
+		clock.Advance(t, 1, time.Minute)
+		clock.Advance(t, 1, time.Minute)
+		// The third timer proves the second tick was handled.
+		clock.Advance(t, 1, 0)
+		close(release)
+
+		// A queued run still pending at Stop is dropped, so let it start.
+		for deadline := time.Now().Add(10 * time.Second); runs.Load() < tt.wantRuns && time.Now().Before(deadline); {
+			time.Sleep(time.Millisecond)
+		}
+
+		if err := a.Stop(context.Background()); err != nil {
+			t.Fatalf("Stop() = %v", err)
+		}
+
+		if n := runs.Load(); n != tt.wantRuns {
+			t.Errorf("overlap %d: runs = %d, want %d", tt.overlap, n, tt.wantRuns)
+		}
+		if n := maxRunning.Load(); n != tt.wantMaxRuns {
+			t.Errorf("overlap %d: max concurrent runs = %d, want %d", tt.overlap, n, tt.wantMaxRuns)
+		}
+		skipped := false
+		for _, err := range logger.errors() {
+			skipped = skipped || errors.Is(err, app.ErrJobSkipped)
+		}
+		if skipped != tt.wantSkipped {
+			t.Errorf("overlap %d: skipped reported = %v, want %v", tt.overlap, skipped, tt.wantSkipped)
+		}
+	}
+}
+
+func TestSchedulerStopWaitsForRuns(t *testing.T) {
+	clock := newFakeClock()
+	started := make(chan struct{})
+	var finished atomic.Bool
+
+	a, _ := schedulerApp(t, clock, app.Job{
+		Name:     "long",
+		Schedule: app.Every(time.Minute),
+		Timeout:  time.Minute,
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			time.Sleep(20 * time.Millisecond)
+			finished.Store(true)
+			return ctx.Err()
+		},
+	})
+
+	clock.Advance(t, 1, time.Minute)
+	waitClosed(t, started, "job did not run")
+
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Stop() returned before the run in flight finished")
+	}
+}
+
+func TestCron(t *testing.T) {
+	s, err := app.Cron("*/15 * * * *")
+	if err != nil {
+		t.Fatalf("Cron() = %v", err)
+	}
+	from := time.Date(2026, 1, 1, 10, 7, 0, 0, time.UTC)
+	if got, want := s.Next(from), time.Date(2026, 1, 1, 10, 15, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", from, got, want)
+	}
+
+	if _, err := app.Cron("not a cron"); err == nil {
+		t.Error("Cron() = nil, want parse error")
+	}
+}
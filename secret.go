@@ -0,0 +1,254 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+)
+
+// SecretResolver resolves secret references of one scheme. LoadConfig
+// replaces string values of the form "scheme://ref" whose scheme has a
+// resolver with the secret the resolver returns for ref, in fields marked
+// secret as for WriteConfig, e.g. tagged `secret:"true"`, and in everything
+// below them. A reference in any other field fails LoadConfig with an error
+// naming the field, unless the field is tagged `secret:"false"`, which keeps
+// values like file:///etc/hostname as they are. It runs after the config
+// files and the environment were applied, so references may come from
+// either.
+//
+// Config.SecretResolvers maps schemes to resolvers. "file" (FileSecretResolver)
+// and "env", which reads the same environment LoadConfig parses, are built
+// in; a nil entry disables a scheme. ExecSecretResolver runs commands and is
+// only used when registered, e.g. for "exec".
+//
+// Fields holding resolved secrets are reported as SourceSecret by Explain
+// and masked by PrintConfig.
+type SecretResolver interface {
+	ResolveSecret(ctx context.Context, ref string) (string, error)
+}
+
+// SecretResolverFunc adapts a function to a SecretResolver.
+type SecretResolverFunc func(ctx context.Context, ref string) (string, error)
+
+func (f SecretResolverFunc) ResolveSecret(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// FileSecretResolver reads a secret from the file at ref, e.g.
+// file:///run/secrets/db_password, dropping trailing line breaks.
+type FileSecretResolver struct{}
+
+func (FileSecretResolver) ResolveSecret(_ context.Context, ref string) (string, error) {
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// ExecSecretResolver runs ref as a command, split on white space and without
+// a shell, and returns its output without trailing line breaks, e.g.
+// exec://pass show db.
+type ExecSecretResolver struct{}
+
+func (ExecSecretResolver) ResolveSecret(ctx context.Context, ref string) (string, error) {
+	args := strings.Fields(ref)
+	if len(args) == 0 {
+		return "", errors.New("empty command")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// FakeSecretResolver resolves refs from a fixed set of secrets, for tests.
+type FakeSecretResolver map[string]string
+
+func (f FakeSecretResolver) ResolveSecret(_ context.Context, ref string) (string, error) {
+	secret, ok := f[ref]
+	if !ok {
+		return "", fmt.Errorf("no secret %q", ref)
+	}
+	return secret, nil
+}
+
+// resolveEnvSecret reads the variable named ref from the environment of the
+// LoadConfig call.
+func (app *BaseApp) resolveEnvSecret(ctx context.Context, ref string) (string, error) {
+	value, ok := lookupEnv(app.envOptionsFrom(ctx))(ref)
+	if !ok {
+		return "", fmt.Errorf("variable %s is not set", ref)
+	}
+	return value, nil
+}
+
+// newSecretResolvers returns the built-in resolvers keyed by scheme,
+// overridden and extended by custom. A resolver set to nil in custom
+// disables the scheme.
+func (app *BaseApp) newSecretResolvers(custom map[string]SecretResolver) map[string]SecretResolver {
+	resolvers := map[string]SecretResolver{
+		"file": FileSecretResolver{},
+		"env":  SecretResolverFunc(app.resolveEnvSecret),
+	}
+	for scheme, resolver := range custom {
+		if resolver == nil {
+			delete(resolvers, scheme)
+			continue
+		}
+		resolvers[scheme] = resolver
+	}
+	return resolvers
+}
+
+// resolveSecrets resolves the secret references in out and returns them by
+// the Go path of the field holding them.
+func (app *BaseApp) resolveSecrets(ctx context.Context, out any) (map[string]string, error) {
+	refs := make(map[string]string)
+	if len(app.secretResolvers) == 0 {
+		return refs, nil
+	}
+
+	err := app.resolveValue(ctx, reflect.ValueOf(out), "", secretUnmarked, refs)
+	return refs, err
+}
+
+// secretMark tells how resolveValue treats references in a field.
+type secretMark int
+
+const (
+	// secretUnmarked fields must not hold references.
+	secretUnmarked secretMark = iota
+	// secretMarked fields, and everything below them, have references
+	// resolved.
+	secretMarked
+	// secretLiteral fields, tagged `secret:"false"`, keep references as is.
+	secretLiteral
+)
+
+// markOf returns the mark of sf within a field marked mark.
+func markOf(sf reflect.StructField, mark secretMark) secretMark {
+	switch {
+	case sf.Tag.Get("secret") == "false":
+		return secretLiteral
+	case isSecretField(sf):
+		return secretMarked
+	}
+	return mark
+}
+
+// resolveValue resolves the references in v, a field marked mark.
+func (app *BaseApp) resolveValue(ctx context.Context, v reflect.Value, field string, mark secretMark, refs map[string]string) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if mark == secretLiteral || !v.CanSet() {
+			return nil
+		}
+		if mark == secretUnmarked {
+			return app.checkUnmarked(field, v.String())
+		}
+		secret, ref, err := app.resolveSecret(ctx, v.String())
+		if err != nil {
+			return fmt.Errorf("app: failed to resolve secret of %s from %s: %w", field, ref, err)
+		}
+		if ref != "" {
+			v.SetString(secret)
+			refs[field] = ref
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			sf := v.Type().Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+
+			name := field
+			if !sf.Anonymous {
+				if name != "" {
+					name += "."
+				}
+				name += sf.Name
+			}
+
+			if err := app.resolveValue(ctx, v.Field(i), name, markOf(sf, mark), refs); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := app.resolveValue(ctx, v.Index(i), field, mark, refs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if mark == secretLiteral || v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if mark == secretUnmarked {
+				if err := app.checkUnmarked(fmt.Sprintf("%s[%v]", field, iter.Key()), iter.Value().String()); err != nil {
+					return err
+				}
+				continue
+			}
+			secret, ref, err := app.resolveSecret(ctx, iter.Value().String())
+			if err != nil {
+				return fmt.Errorf("app: failed to resolve secret of %s[%v] from %s: %w", field, iter.Key(), ref, err)
+			}
+			if ref != "" {
+				v.SetMapIndex(iter.Key(), reflect.ValueOf(secret).Convert(v.Type().Elem()))
+				refs[field] = ref
+			}
+		}
+	}
+	return nil
+}
+
+// checkUnmarked fails for a reference of a known scheme in a field not
+// marked secret, which would otherwise silently keep it.
+func (app *BaseApp) checkUnmarked(field, s string) error {
+	scheme, _, ok := strings.Cut(s, "://")
+	if _, known := app.secretResolvers[scheme]; !ok || !known {
+		return nil
+	}
+	return fmt.Errorf("app: config field %s holds secret reference %s but is not marked secret; "+
+		"tag it `secret:\"true\"` to resolve it or `secret:\"false\"` to keep it", field, s)
+}
+
+// resolveSecret resolves s when it is a reference of a known scheme. ref is
+// empty when s is a plain value.
+func (app *BaseApp) resolveSecret(ctx context.Context, s string) (secret, ref string, err error) {
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok {
+		return "", "", nil
+	}
+	resolver, ok := app.secretResolvers[scheme]
+	if !ok {
+		return "", "", nil
+	}
+
+	secret, err = resolver.ResolveSecret(ctx, rest)
+	return secret, s, err
+}
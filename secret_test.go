@@ -0,0 +1,127 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/caarlos0/env/v11"
+
+	"github.com/rumorsflow/app"
+)
+
+type credsConfig struct {
+	User     string            `json:"user"`
+	DSN      string            `json:"dsn"`
+	Password string            `json:"password" secret:"true"`
+	Token    string            `json:"token" secret:"true"`
+	Headers  map[string]string `json:"headers" secret:"true"`
+}
+
+func TestLoadConfigSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+
+	a := configApp(app.Config{
+		ConfigRaw:       []byte(`{"user":"admin","password":"file://` + path + `","token":"env://API_TOKEN","headers":{"auth":"vault://auth"}}`),
+		EnvOptions:      &env.Options{Environment: map[string]string{"API_TOKEN": "t0ken"}},
+		SecretResolvers: map[string]app.SecretResolver{"vault": app.FakeSecretResolver{"auth": "Bearer x"}},
+	})
+	a.OnBoot().BindFunc(app.LoadConfig[credsConfig]())
+	if err := a.Boot(context.Background()); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+
+	var cfg credsConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if cfg.User != "admin" || cfg.Password != "hunter2" || cfg.Token != "t0ken" || cfg.Headers["auth"] != "Bearer x" {
+		t.Errorf("LoadConfig() = %+v, want resolved secrets", cfg)
+	}
+
+	o, ok := a.Explain(cfg).Lookup("Password")
+	if !ok || o.Source != app.SourceSecret || o.Key != "file://"+path {
+		t.Errorf("Explain(Password) = %v, want secret from file://%s", o, path)
+	}
+
+	var buf bytes.Buffer
+	if err := a.PrintConfig(&buf, app.FormatJSON); err != nil {
+		t.Fatalf("PrintConfig() = %v", err)
+	}
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "t0ken", "Bearer x"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("PrintConfig() = %s, leaks %q", out, secret)
+		}
+	}
+	if !strings.Contains(out, "admin") {
+		t.Errorf("PrintConfig() = %s, want plain values kept", out)
+	}
+}
+
+type literalConfig struct {
+	DSN string `json:"dsn" secret:"false"`
+}
+
+func TestLoadConfigSecretInUnmarkedField(t *testing.T) {
+	a := configApp(app.Config{ConfigRaw: []byte(`{"user":"env://USER","dsn":"file:///etc/hostname"}`)})
+
+	err := a.LoadConfig(context.Background(), &credsConfig{})
+	if err == nil || !strings.Contains(err.Error(), "User") || !strings.Contains(err.Error(), "env://USER") {
+		t.Errorf("LoadConfig() = %v, want error naming the unmarked field", err)
+	}
+
+	var cfg literalConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if cfg.DSN != "file:///etc/hostname" {
+		t.Errorf("DSN = %q, want the reference kept in a field tagged secret:\"false\"", cfg.DSN)
+	}
+}
+
+func TestLoadConfigSecretError(t *testing.T) {
+	a := configApp(app.Config{
+		ConfigRaw:       []byte(`{"password":"vault://missing"}`),
+		SecretResolvers: map[string]app.SecretResolver{"vault": app.FakeSecretResolver{}},
+	})
+
+	err := a.LoadConfig(context.Background(), &credsConfig{})
+	if err == nil || !strings.Contains(err.Error(), "Password") || !strings.Contains(err.Error(), "vault://missing") {
+		t.Errorf("LoadConfig() = %v, want error naming the field and the reference", err)
+	}
+}
+
+func TestLoadConfigSecretSchemeDisabled(t *testing.T) {
+	a := configApp(app.Config{
+		ConfigRaw:       []byte(`{"password":"file:///nonexistent"}`),
+		SecretResolvers: map[string]app.SecretResolver{"file": nil},
+	})
+
+	var cfg credsConfig
+	if err := a.LoadConfig(context.Background(), &cfg); err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if cfg.Password != "file:///nonexistent" {
+		t.Errorf("Password = %q, want the value kept as is", cfg.Password)
+	}
+}
+
+func TestExecSecretResolver(t *testing.T) {
+	var r app.ExecSecretResolver
+
+	secret, err := r.ResolveSecret(context.Background(), "echo s3cret")
+	if err != nil || secret != "s3cret" {
+		t.Errorf("ResolveSecret() = %q, %v, want s3cret", secret, err)
+	}
+
+	if _, err := r.ResolveSecret(context.Background(), ""); err == nil {
+		t.Error("ResolveSecret() = nil, want error for an empty command")
+	}
+}
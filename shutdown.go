@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Default stop phases, in the order they run. StopFx stops the fx app, so
+// the OnStop hooks of components outside the other phases run there.
+const (
+	StopIngress = "ingress"
+	StopWorkers = "workers"
+	StopFx      = "fx"
+	StopStorage = "storage"
+)
+
+// StopPhase is a named step of the shutdown sequence.
+type StopPhase struct {
+	Name string
+	// Timeout is the phase's share of StopTimeout. When zero, the phase gets
+	// an equal share of what the set timeouts leave of StopTimeout, split
+	// among the phases with hooks; StopFx always takes one.
+	Timeout time.Duration
+}
+
+func defaultStopPhases() []StopPhase {
+	return []StopPhase{{Name: StopIngress}, {Name: StopWorkers}, {Name: StopFx}, {Name: StopStorage}}
+}
+
+// PhaseError reports a stop phase that failed or overran its budget.
+type PhaseError struct {
+	Phase string
+	Err   error
+}
+
+func (e *PhaseError) Error() string {
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		return fmt.Sprintf("app: stop phase %s overran its budget: %v", e.Phase, e.Err)
+	}
+	return fmt.Sprintf("app: stop phase %s: %v", e.Phase, e.Err)
+}
+
+// Unwrap also yields ErrStopTimeout for a phase that overran.
+func (e *PhaseError) Unwrap() []error {
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		return []error{ErrStopTimeout, e.Err}
+	}
+	return []error{e.Err}
+}
+
+// Shutdown orders how components stop. Stop and Restart first mark the app
+// not ready, wait for the drain delay so load balancers notice, then run the
+// phases in order, each bounded by its budget. The fx OnStop hooks run in the
+// StopFx phase, which is added last when the configured phases leave it out.
+// It is supplied to the fx graph by Boot:
+//
+//	fx.Invoke(func(s *app.Shutdown, srv *http.Server) error {
+//		return s.Append(app.StopIngress, srv.Shutdown)
+//	})
+type Shutdown struct {
+	mu     sync.Mutex
+	phases []StopPhase
+	hooks  map[string][]func(context.Context) error
+	drain  time.Duration
+}
+
+func newShutdown(phases []StopPhase, drain time.Duration) *Shutdown {
+	if len(phases) == 0 {
+		phases = defaultStopPhases()
+	}
+	phases = slices.Clone(phases)
+	if !slices.ContainsFunc(phases, func(p StopPhase) bool { return p.Name == StopFx }) {
+		phases = append(phases, StopPhase{Name: StopFx})
+	}
+	return &Shutdown{
+		phases: phases,
+		hooks:  make(map[string][]func(context.Context) error),
+		drain:  drain,
+	}
+}
+
+// Append adds fn to phase. Within a phase, hooks run in reverse order like
+// fx OnStop hooks.
+func (s *Shutdown) Append(phase string, fn func(ctx context.Context) error) error {
+	if !slices.ContainsFunc(s.phases, func(p StopPhase) bool { return p.Name == phase }) {
+		return fmt.Errorf("app: unknown stop phase %q", phase)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hooks[phase] = append(s.hooks[phase], fn)
+
+	return nil
+}
+
+// DrainDelay is the wait between turning not ready and stopping anything.
+func (s *Shutdown) DrainDelay() time.Duration {
+	return s.drain
+}
+
+// run drains and stops the phases within ctx, whose deadline covers the
+// drain delay plus total, with stopFx ending the StopFx phase. Every phase
+// runs even if an earlier one failed.
+func (s *Shutdown) run(ctx context.Context, app *BaseApp, total time.Duration, stopFx func(context.Context) error) error {
+	if s.drain > 0 {
+		t := time.NewTimer(s.drain)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+		}
+	}
+
+	s.mu.Lock()
+	hooks := make(map[string][]func(context.Context) error, len(s.hooks))
+	for phase, fns := range s.hooks {
+		hooks[phase] = slices.Clone(fns)
+	}
+	s.mu.Unlock()
+
+	// Hooks run in reverse order, so stopFx comes after those appended to
+	// the phase.
+	hooks[StopFx] = append([]func(context.Context) error{stopFx}, hooks[StopFx]...)
+
+	// Phases without hooks are skipped and take no share of total.
+	rest, shares := total, 0
+	for _, phase := range s.phases {
+		switch {
+		case len(hooks[phase.Name]) == 0:
+		case phase.Timeout > 0:
+			rest -= phase.Timeout
+		default:
+			shares++
+		}
+	}
+	rest = max(rest, 0)
+
+	var errs []error
+	for _, phase := range s.phases {
+		fns := hooks[phase.Name]
+		if len(fns) == 0 {
+			continue
+		}
+
+		budget := phase.Timeout
+		if budget <= 0 {
+			budget = rest / time.Duration(shares)
+		}
+
+		start := time.Now()
+		err := runPhase(ctx, budget, fns)
+		t := HookTiming{Phase: PhaseStop, Name: "stop phase " + phase.Name, Caller: app.name, Duration: time.Since(start)}
+		if err != nil {
+			err = &PhaseError{Phase: phase.Name, Err: err}
+			t.Err = err.Error()
+			errs = append(errs, err)
+		}
+		app.recordTiming(t)
+	}
+
+	return errors.Join(errs...)
+}
+
+func runPhase(ctx context.Context, budget time.Duration, fns []func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+
+	var errs []error
+	for i := len(fns) - 1; i >= 0; i-- {
+		errc := make(chan error, 1)
+		go func() { errc <- fns[i](ctx) }()
+
+		select {
+		case err := <-errc:
+			errs = append(errs, err)
+		case <-ctx.Done():
+			// A hook ignoring its context must not hold up the next phases.
+			return errors.Join(append(errs, ctx.Err())...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (app *BaseApp) Shutdown() *Shutdown {
+	return app.shutdown
+}
@@ -0,0 +1,156 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/rumorsflow/app"
+)
+
+func TestShutdownPhases(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	var stopCalled time.Time
+	var drained time.Duration
+	var ready app.HealthStatus
+
+	a := app.NewBaseApp(app.Config{
+		StartTimeout: 10 * time.Second,
+		StopTimeout:  10 * time.Second,
+		DrainDelay:   50 * time.Millisecond,
+	})
+	a.OnBoot().BindFunc(app.Options(fx.Invoke(func(lc fx.Lifecycle, s *app.Shutdown) error {
+		lc.Append(fx.StopHook(record("fx")))
+		return errors.Join(
+			s.Append(app.StopStorage, record("storage")),
+			s.Append(app.StopWorkers, record("workers")),
+			s.Append(app.StopIngress, record("ingress 1")),
+			s.Append(app.StopIngress, func(ctx context.Context) error {
+				drained = time.Since(stopCalled)
+				ready = a.Health().Readiness(ctx).Status
+				return record("ingress 2")(ctx)
+			}),
+		)
+	})))
+
+	ctx := context.Background()
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	stopCalled = time.Now()
+	if err := a.Stop(ctx); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+
+	want := []string{"ingress 2", "ingress 1", "workers", "fx", "storage"}
+	if !slices.Equal(order, want) {
+		t.Errorf("stop order = %v, want %v", order, want)
+	}
+	if drained < 50*time.Millisecond {
+		t.Errorf("ingress stopped %v after Stop, want after the 50ms drain delay", drained)
+	}
+	if ready != app.StatusDown {
+		t.Errorf("Readiness() during ingress = %v, want %v", ready, app.StatusDown)
+	}
+	if _, ok := findTiming(a.Timings(), app.PhaseStop, "stop phase fx"); !ok {
+		t.Errorf("Timings() = %+v, want stop phase timings", a.Timings())
+	}
+}
+
+func TestShutdownPhaseOverrun(t *testing.T) {
+	storageStopped := make(chan struct{})
+
+	a := app.NewBaseApp(app.Config{
+		StartTimeout: 10 * time.Second,
+		StopTimeout:  10 * time.Second,
+		StopPhases:   []app.StopPhase{{Name: app.StopIngress, Timeout: 30 * time.Millisecond}, {Name: app.StopStorage}},
+	})
+	a.OnBoot().BindFunc(app.Options(fx.Invoke(func(s *app.Shutdown) error {
+		return errors.Join(
+			s.Append(app.StopIngress, waitDone),
+			s.Append(app.StopStorage, func(context.Context) error {
+				close(storageStopped)
+				return nil
+			}),
+		)
+	})))
+
+	ctx := context.Background()
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	err := a.Stop(ctx)
+
+	var phaseErr *app.PhaseError
+	if !errors.As(err, &phaseErr) || phaseErr.Phase != app.StopIngress {
+		t.Fatalf("Stop() = %v, want ingress PhaseError", err)
+	}
+	if !errors.Is(err, app.ErrStopTimeout) {
+		t.Errorf("Stop() = %v, want %v", err, app.ErrStopTimeout)
+	}
+	waitClosed(t, storageStopped, "storage phase did not run after ingress overran")
+}
+
+func TestShutdownBudgetSkipsEmptyPhases(t *testing.T) {
+	var budget time.Duration
+
+	a := app.NewBaseApp(app.Config{
+		StartTimeout: 10 * time.Second,
+		StopTimeout:  4 * time.Second,
+		StopPhases:   []app.StopPhase{{Name: app.StopIngress, Timeout: time.Second}, {Name: app.StopStorage}},
+	})
+	a.OnBoot().BindFunc(app.Options(fx.Invoke(func(s *app.Shutdown) error {
+		return s.Append(app.StopStorage, func(ctx context.Context) error {
+			deadline, _ := ctx.Deadline()
+			budget = time.Until(deadline)
+			return nil
+		})
+	})))
+
+	ctx := context.Background()
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if err := a.Stop(ctx); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+
+	// The empty ingress phase leaves its second to storage and the fx
+	// phase, which is added last.
+	if budget <= 1200*time.Millisecond || budget > 2*time.Second {
+		t.Errorf("storage budget = %v, want half of the 4s StopTimeout", budget)
+	}
+}
+
+func TestShutdownUnknownPhase(t *testing.T) {
+	err := newApp(t).Shutdown().Append("cleanup", func(context.Context) error { return nil })
+	if err == nil {
+		t.Error("Append() = nil, want unknown phase error")
+	}
+}
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime/pprof"
+	"syscall"
+
+	"github.com/gowool/hook"
+	"go.uber.org/fx"
+)
+
+// SignalAction is what Run does with a mapped signal. Each signal is handled
+// on its own goroutine, so a hung action or shutdown does not block the next
+// signal. Errors are returned to the OnSignal handlers; Run itself keeps going
+// unless the action stopped the app.
+type SignalAction func(event *SignalEvent) error
+
+// SignalEvent is triggered by Run for every mapped signal. Handlers may
+// replace or clear Action before calling Next.
+type SignalEvent struct {
+	hook.Event
+	App    App
+	Ctx    context.Context
+	Signal os.Signal
+	Action SignalAction
+}
+
+// defaultSignals are the mappings Run starts from. SIGINT and SIGTERM are
+// not listed: fx handles them and Run stops unless they are mapped.
+// fx.Shutdowner requests, which fx reports as SIGTERM, stop Run either way.
+func defaultSignals() map[os.Signal]SignalAction {
+	return map[os.Signal]SignalAction{
+		syscall.SIGUSR1: SignalRestart,
+		syscall.SIGHUP:  SignalReload,
+	}
+}
+
+// newSignals merges the configured mappings into the defaults. A nil action
+// removes a default, e.g. the SIGUSR1 restart where exec is unsafe; the
+// package-level Restart then fails rather than sending SIGUSR1.
+func newSignals(custom map[os.Signal]SignalAction) map[os.Signal]SignalAction {
+	signals := defaultSignals()
+	for sig, action := range custom {
+		if action == nil {
+			delete(signals, sig)
+			continue
+		}
+		signals[sig] = action
+	}
+	return signals
+}
+
+// signalLogger is implemented by apps that report signals to the fx logger.
+type signalLogger interface {
+	logSignal(sig os.Signal)
+}
+
+func logSignal(event *SignalEvent) {
+	if l, ok := event.App.(signalLogger); ok {
+		l.logSignal(event.Signal)
+	}
+}
+
+// SignalStop stops the app; Run returns the outcome.
+func SignalStop(event *SignalEvent) error {
+	logSignal(event)
+	return event.App.Stop(event.Ctx)
+}
+
+// SignalRestart restarts the app in place; Run returns only if it fails.
+func SignalRestart(event *SignalEvent) error {
+	logSignal(event)
+	return event.App.Restart(event.Ctx)
+}
+
+// SignalReload reloads the registered configs. A failed reload keeps the
+// running config; OnReload handlers have already seen the error.
+func SignalReload(event *SignalEvent) error {
+	r, ok := event.App.(Reloader)
+	if !ok {
+		return fmt.Errorf("app: %T cannot reload", event.App)
+	}
+	return r.Reload(event.Ctx)
+}
+
+// SignalDumpGoroutines writes the stacks of all goroutines to w.
+func SignalDumpGoroutines(w io.Writer) SignalAction {
+	return func(*SignalEvent) error {
+		return pprof.Lookup("goroutine").WriteTo(w, 2)
+	}
+}
+
+// SignalToggleDebug switches level between slog.LevelDebug and base, so a
+// running service can be made verbose and back with the same signal.
+func SignalToggleDebug(level *slog.LevelVar, base slog.Level) SignalAction {
+	return func(*SignalEvent) error {
+		if level.Level() == slog.LevelDebug {
+			level.Set(base)
+		} else {
+			level.Set(slog.LevelDebug)
+		}
+		return nil
+	}
+}
+
+// SignalRotateLogs calls rotate, typically the reopen or rotate method of
+// the log writer, after an external tool such as logrotate moved the file.
+func SignalRotateLogs(rotate func() error) SignalAction {
+	return func(*SignalEvent) error {
+		if err := rotate(); err != nil {
+			return fmt.Errorf("app: unable to rotate logs: %w", err)
+		}
+		return nil
+	}
+}
+
+// SignalFunc adapts a plain callback.
+func SignalFunc(fn func(ctx context.Context) error) SignalAction {
+	return func(event *SignalEvent) error {
+		return fn(event.Ctx)
+	}
+}
+
+func (app *BaseApp) OnSignal() *hook.Hook[*SignalEvent] {
+	return app.onSignal
+}
+
+// recordingShutdowner marks shutdowns requested through fx.Shutdowner, so
+// Run can tell them from a SIGTERM, which fx reports them as.
+type recordingShutdowner struct {
+	fx.Shutdowner
+	app *BaseApp
+}
+
+func (app *BaseApp) recordShutdowner(s fx.Shutdowner) fx.Shutdowner {
+	return &recordingShutdowner{Shutdowner: s, app: app}
+}
+
+func (s *recordingShutdowner) Shutdown(opts ...fx.ShutdownOption) error {
+	s.app.shutdownRequested.Store(true)
+	return s.Shutdowner.Shutdown(opts...)
+}
+
+func (app *BaseApp) handleSignal(ctx context.Context, sig os.Signal) error {
+	event := &SignalEvent{App: app, Ctx: ctx, Signal: sig, Action: app.signals[sig]}
+
+	return app.OnSignal().Trigger(event, func(event *SignalEvent) error {
+		if event.Action != nil {
+			if err := event.Action(event); err != nil {
+				return err
+			}
+		}
+		return event.Next()
+	})
+}
@@ -0,0 +1,210 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/rumorsflow/app"
+)
+
+func signalApp(signals map[os.Signal]app.SignalAction) *app.BaseApp {
+	return configApp(app.Config{
+		StartTimeout: 10 * time.Second,
+		StopTimeout:  10 * time.Second,
+		Signals:      signals,
+	})
+}
+
+// sendUntil re-sends sig until done is closed, since Run registers its
+// handlers only after Start.
+func sendUntil(t *testing.T, sig syscall.Signal, done <-chan struct{}) {
+	t.Helper()
+
+	deadline := time.After(10 * time.Second)
+	for {
+		select {
+		case <-done:
+			return
+		case <-deadline:
+			t.Fatalf("no reaction to %v", sig)
+		case <-time.After(50 * time.Millisecond):
+			if err := syscall.Kill(os.Getpid(), sig); err != nil {
+				t.Fatalf("kill: %v", err)
+			}
+		}
+	}
+}
+
+func TestRunCustomSignals(t *testing.T) {
+	// Same rationale as the SIGTERM safety net in app_test.go.
+	safety := make(chan os.Signal, 1)
+	signal.Notify(safety, syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGTERM)
+
+	fired := make(chan struct{})
+	termed := make(chan struct{})
+	a := signalApp(map[os.Signal]app.SignalAction{
+		syscall.SIGUSR1: nil,
+		syscall.SIGUSR2: app.SignalFunc(func(context.Context) error {
+			select {
+			case <-fired:
+			default:
+				close(fired)
+			}
+			return nil
+		}),
+		syscall.SIGTERM: func(*app.SignalEvent) error {
+			select {
+			case <-termed:
+			default:
+				close(termed)
+			}
+			return nil
+		},
+	})
+
+	runErr, started := startRun(t, a)
+	waitClosed(t, started, "app did not start")
+
+	// SIGUSR1 no longer restarts.
+	for range 3 {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+			t.Fatalf("kill: %v", err)
+		}
+	}
+
+	sendUntil(t, syscall.SIGUSR2, fired)
+	sendUntil(t, syscall.SIGTERM, termed)
+
+	// Nor does the package-level Restart, which refuses to send it.
+	if err := app.Restart(); !errors.Is(err, app.ErrRestartUnsupported) {
+		t.Errorf("Restart() = %v, want %v", err, app.ErrRestartUnsupported)
+	}
+
+	if s := a.State(); s != app.StateRunning {
+		t.Fatalf("State() = %v, want running", s)
+	}
+
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if err := waitErr(t, runErr); err != nil {
+		t.Errorf("Run() = %v, want nil", err)
+	}
+}
+
+func TestRunSignalStopAction(t *testing.T) {
+	safety := make(chan os.Signal, 1)
+	signal.Notify(safety, syscall.SIGUSR2)
+
+	a := signalApp(map[os.Signal]app.SignalAction{syscall.SIGUSR2: app.SignalStop})
+	runErr, started := startRun(t, a)
+	waitClosed(t, started, "app did not start")
+
+	sendUntil(t, syscall.SIGUSR2, a.Done())
+
+	if err := waitErr(t, runErr); err != nil {
+		t.Errorf("Run() = %v, want nil", err)
+	}
+}
+
+func TestRunShutdownerWithSIGTERMMapped(t *testing.T) {
+	safety := make(chan os.Signal, 1)
+	signal.Notify(safety, syscall.SIGTERM)
+
+	var shutdowner fx.Shutdowner
+	a := signalApp(map[os.Signal]app.SignalAction{
+		syscall.SIGTERM: func(*app.SignalEvent) error { return nil },
+	})
+	a.OnBoot().BindFunc(app.Options(fx.Populate(&shutdowner)))
+
+	runErr, started := startRun(t, a)
+	waitClosed(t, started, "app did not start")
+
+	if err := shutdowner.Shutdown(); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+	if err := waitErr(t, runErr); err != nil {
+		t.Errorf("Run() = %v, want nil", err)
+	}
+}
+
+func TestOnSignalOverridesAction(t *testing.T) {
+	safety := make(chan os.Signal, 1)
+	signal.Notify(safety, syscall.SIGUSR2)
+
+	a := signalApp(map[os.Signal]app.SignalAction{syscall.SIGUSR2: app.SignalStop})
+
+	seen := make(chan struct{})
+	a.OnSignal().BindFunc(func(e *app.SignalEvent) error {
+		if e.Signal == syscall.SIGUSR2 {
+			e.Action = nil
+			select {
+			case <-seen:
+			default:
+				close(seen)
+			}
+		}
+		return e.Next()
+	})
+
+	runErr, started := startRun(t, a)
+	waitClosed(t, started, "app did not start")
+
+	sendUntil(t, syscall.SIGUSR2, seen)
+
+	if s := a.State(); s != app.StateRunning {
+		t.Fatalf("State() = %v, want running", s)
+	}
+
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if err := waitErr(t, runErr); err != nil {
+		t.Errorf("Run() = %v, want nil", err)
+	}
+}
+
+func TestSignalActions(t *testing.T) {
+	event := &app.SignalEvent{Ctx: context.Background(), Signal: syscall.SIGUSR2}
+
+	t.Run("dump goroutines", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := app.SignalDumpGoroutines(&buf)(event); err != nil {
+			t.Fatalf("action = %v", err)
+		}
+		if !strings.Contains(buf.String(), "goroutine ") {
+			t.Errorf("dump = %q, want goroutine stacks", buf.String())
+		}
+	})
+
+	t.Run("toggle debug", func(t *testing.T) {
+		var level slog.LevelVar
+		action := app.SignalToggleDebug(&level, slog.LevelInfo)
+
+		_ = action(event)
+		if level.Level() != slog.LevelDebug {
+			t.Errorf("level = %v, want debug", level.Level())
+		}
+		_ = action(event)
+		if level.Level() != slog.LevelInfo {
+			t.Errorf("level = %v, want info", level.Level())
+		}
+	})
+
+	t.Run("rotate logs", func(t *testing.T) {
+		err := app.SignalRotateLogs(func() error { return errSentinel })(event)
+		if !errors.Is(err, errSentinel) {
+			t.Errorf("action = %v, want %v", err, errSentinel)
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gowool/hook"
+)
+
+// State is a stage of the application lifecycle.
+type State int32
+
+const (
+	StateNew State = iota
+	StateBooting
+	StateBooted
+	StateStarting
+	StateRunning
+	StateStopping
+	StateRestarting
+	StateStopped
+)
+
+func (s State) String() string {
+	switch s {
+	case StateNew:
+		return "new"
+	case StateBooting:
+		return "booting"
+	case StateBooted:
+		return "booted"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateRestarting:
+		return "restarting"
+	case StateStopped:
+		return "stopped"
+	default:
+		return fmt.Sprintf("State(%d)", int32(s))
+	}
+}
+
+// rank orders states along the lifecycle; stopping and restarting are
+// alternative paths to the same point.
+func (s State) rank() int {
+	if s > StateStopping {
+		return int(s) - 1
+	}
+	return int(s)
+}
+
+// StateError reports a lifecycle operation attempted in a state that does
+// not allow it, e.g. Start before Boot or Start after Stop.
+type StateError struct {
+	Op    string
+	State State
+}
+
+func (e *StateError) Error() string {
+	if e.State == StateNew {
+		return fmt.Sprintf("app: cannot %s: not booted", e.Op)
+	}
+	return fmt.Sprintf("app: cannot %s: app is %s", e.Op, e.State)
+}
+
+// Is reports a StateError for an app that was never booted as ErrNotBooted.
+func (e *StateError) Is(target error) bool {
+	return target == ErrNotBooted && e.State == StateNew
+}
+
+type StateChangeEvent struct {
+	hook.Event
+	App  App
+	From State
+	To   State
+}
+
+// Lifecycle is implemented by apps that expose their lifecycle state, like
+// BaseApp. Components that receive the app as App assert to it to wait for
+// a state or to follow transitions.
+type Lifecycle interface {
+	State() State
+	Wait(s State) <-chan struct{}
+	Done() <-chan struct{}
+	OnStateChange() *hook.Hook[*StateChangeEvent]
+}
+
+var _ Lifecycle = (*BaseApp)(nil)
+
+// envRestarts counts the exec restarts behind the current process.
+const envRestarts = "APP_RESTARTS"
+
+// Status is a snapshot of the application identity and lifecycle.
+type Status struct {
+	Name    string        `json:"name"`
+	Version string        `json:"version"`
+	State   string        `json:"state"`
+	Uptime  time.Duration `json:"uptime"`
+	// Restarts counts the exec restarts since the process was first started.
+	Restarts int `json:"restarts"`
+}
+
+// StatusReporter is implemented by apps that report their status, like
+// BaseApp. The admin server serves it on /status.
+type StatusReporter interface {
+	Status() Status
+}
+
+var _ StatusReporter = (*BaseApp)(nil)
+
+func (app *BaseApp) Status() Status {
+	app.stateMu.Lock()
+	defer app.stateMu.Unlock()
+
+	s := Status{
+		Name:     app.name,
+		Version:  app.version,
+		State:    app.state.String(),
+		Restarts: app.restarts,
+	}
+	if app.state == StateRunning {
+		s.Uptime = time.Since(app.startedAt)
+	}
+	return s
+}
+
+func (app *BaseApp) OnStateChange() *hook.Hook[*StateChangeEvent] {
+	return app.onStateChange
+}
+
+func (app *BaseApp) State() State {
+	app.stateMu.Lock()
+	defer app.stateMu.Unlock()
+
+	return app.state
+}
+
+// Wait returns a channel closed once the app has reached s or any later
+// state. Check State afterwards: a stopped app releases every waiter, even
+// for states it skipped.
+func (app *BaseApp) Wait(s State) <-chan struct{} {
+	app.stateMu.Lock()
+	defer app.stateMu.Unlock()
+
+	if app.state.rank() >= s.rank() {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}
+
+	ch, ok := app.waiters[s.rank()]
+	if !ok {
+		ch = make(chan struct{})
+		app.waiters[s.rank()] = ch
+	}
+	return ch
+}
+
+// Done returns a channel closed once the app has stopped, whether by Stop,
+// a failed Restart or a rejected shutdown.
+func (app *BaseApp) Done() <-chan struct{} {
+	return app.Wait(StateStopped)
+}
+
+// transition moves the app to state to if it currently is in one of from,
+// or unconditionally when from is empty, releasing waiters and notifying
+// OnStateChange.
+func (app *BaseApp) transition(op string, to State, from ...State) error {
+	app.stateMu.Lock()
+
+	prev := app.state
+	if len(from) > 0 && !slices.Contains(from, prev) {
+		app.stateMu.Unlock()
+		return &StateError{Op: op, State: prev}
+	}
+
+	app.state = to
+	if to == StateRunning {
+		app.startedAt = time.Now()
+	}
+	for rank, ch := range app.waiters {
+		if rank <= to.rank() {
+			close(ch)
+			delete(app.waiters, rank)
+		}
+	}
+
+	app.stateMu.Unlock()
+
+	// Handler errors cannot undo a transition that already happened.
+	_ = app.OnStateChange().Trigger(&StateChangeEvent{App: app, From: prev, To: to})
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go.uber.org/fx"
+
+	"github.com/rumorsflow/app"
+)
+
+func TestStateLifecycle(t *testing.T) {
+	a := newApp(t)
+
+	var mu sync.Mutex
+	var changes []string
+	a.OnStateChange().BindFunc(func(e *app.StateChangeEvent) error {
+		mu.Lock()
+		changes = append(changes, e.From.String()+">"+e.To.String())
+		mu.Unlock()
+		return e.Next()
+	})
+
+	if got := a.State(); got != app.StateNew {
+		t.Fatalf("State() = %v, want %v", got, app.StateNew)
+	}
+
+	running := a.Wait(app.StateRunning)
+	ctx := context.Background()
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+	select {
+	case <-running:
+		t.Fatal("Wait(running) released after Boot")
+	default:
+	}
+
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	waitClosed(t, running, "Wait(running) not released after Start")
+	if got := a.State(); got != app.StateRunning {
+		t.Errorf("State() = %v, want %v", got, app.StateRunning)
+	}
+
+	if err := a.Stop(ctx); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	waitClosed(t, a.Done(), "Done() not released after Stop")
+	if got := a.State(); got != app.StateStopped {
+		t.Errorf("State() = %v, want %v", got, app.StateStopped)
+	}
+
+	want := []string{
+		"new>booting", "booting>booted", "booted>starting",
+		"starting>running", "running>stopping", "stopping>stopped",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != len(want) {
+		t.Fatalf("state changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("state changes = %v, want %v", changes, want)
+			break
+		}
+	}
+}
+
+func TestStateIllegalTransitions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("start before boot", func(t *testing.T) {
+		a := newApp(t)
+
+		var stateErr *app.StateError
+		if err := a.Start(ctx); !errors.As(err, &stateErr) || stateErr.State != app.StateNew {
+			t.Errorf("Start() = %v, want *StateError in state new", err)
+		}
+	})
+
+	t.Run("boot twice", func(t *testing.T) {
+		a := newApp(t)
+		if err := a.Boot(ctx); err != nil {
+			t.Fatalf("Boot() = %v", err)
+		}
+
+		var stateErr *app.StateError
+		if err := a.Boot(ctx); !errors.As(err, &stateErr) || stateErr.State != app.StateBooted {
+			t.Errorf("second Boot() = %v, want *StateError in state booted", err)
+		}
+	})
+
+	t.Run("start after stop", func(t *testing.T) {
+		a := newStartedApp(t)
+		if err := a.Stop(ctx); err != nil {
+			t.Fatalf("Stop() = %v", err)
+		}
+
+		var stateErr *app.StateError
+		if err := a.Start(ctx); !errors.As(err, &stateErr) || stateErr.State != app.StateStopped {
+			t.Errorf("Start() after Stop = %v, want *StateError in state stopped", err)
+		}
+	})
+
+	t.Run("boot failure can be retried", func(t *testing.T) {
+		a := newApp(t)
+		fail := true
+		a.OnBoot().BindFunc(func(e *app.BootEvent) error {
+			if fail {
+				return errSentinel
+			}
+			return e.Next()
+		})
+
+		if err := a.Boot(ctx); !errors.Is(err, errSentinel) {
+			t.Fatalf("Boot() = %v, want %v", err, errSentinel)
+		}
+		if got := a.State(); got != app.StateNew {
+			t.Errorf("State() after failed Boot = %v, want %v", got, app.StateNew)
+		}
+
+		fail = false
+		if err := a.Boot(ctx); err != nil {
+			t.Errorf("Boot() retry = %v", err)
+		}
+	})
+}
+
+func TestWaitReleasedForSkippedStates(t *testing.T) {
+	a := newApp(t)
+	running := a.Wait(app.StateRunning)
+
+	_ = a.Stop(context.Background())
+
+	waitClosed(t, running, "Wait(running) not released after the app stopped")
+	if got := a.State(); got != app.StateStopped {
+		t.Errorf("State() = %v, want %v", got, app.StateStopped)
+	}
+}
+
+func TestLifecycleFromInjectedApp(t *testing.T) {
+	var injected app.App
+	a := newApp(t, fx.Invoke(func(a app.App) { injected = a }))
+
+	ctx := context.Background()
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+
+	lc, ok := injected.(app.Lifecycle)
+	if !ok {
+		t.Fatalf("%T does not implement app.Lifecycle", injected)
+	}
+	if got := lc.State(); got != app.StateBooted {
+		t.Errorf("State() = %v, want %v", got, app.StateBooted)
+	}
+
+	var to app.State
+	lc.OnStateChange().BindFunc(func(e *app.StateChangeEvent) error {
+		to = e.To
+		return e.Next()
+	})
+
+	_ = a.Stop(ctx)
+
+	waitClosed(t, lc.Done(), "Done() not released after the app stopped")
+	if to != app.StateStopped {
+		t.Errorf("last transition to %v, want %v", to, app.StateStopped)
+	}
+}
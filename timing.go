@@ -0,0 +1,286 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/gowool/hook"
+	"go.uber.org/fx/fxevent"
+)
+
+// Lifecycle phases a HookTiming belongs to.
+const (
+	PhaseBoot  = "boot"
+	PhaseStart = "start"
+	PhaseStop  = "stop"
+)
+
+const defaultSlowHookThreshold = 0.5
+
+// HookTiming is the run time of a single lifecycle hook: an fx constructor
+// during boot, an fx OnStart/OnStop hook, or a handler bound to the app's
+// OnBoot, OnStart or OnStop hook, excluding what it runs through Next.
+// Handlers are named by their position in the chain and, once they called
+// Next, their function, e.g. "OnStart#2 main.main.func1".
+type HookTiming struct {
+	Phase    string        `json:"phase"`
+	Name     string        `json:"name"`
+	Caller   string        `json:"caller,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Err      string        `json:"error,omitempty"`
+}
+
+// SlowHookEvent is triggered when a single hook took longer than the slow
+// hook threshold allows.
+type SlowHookEvent struct {
+	hook.Event
+	App    App
+	Timing HookTiming
+	Limit  time.Duration
+}
+
+// OnSlowHook is triggered for every start or stop hook running longer than
+// Config.SlowHookThreshold, a fraction of the phase timeout (0.5 by default),
+// ahead of the phase as a whole timing out. At the end of the chain a warning
+// is logged through the fx event logger, as an fxevent.Run of kind
+// "slow hook"; a handler not calling Next suppresses it.
+func (app *BaseApp) OnSlowHook() *hook.Hook[*SlowHookEvent] {
+	return app.onSlowHook
+}
+
+// Timings returns the hook timings recorded so far, in completion order.
+func (app *BaseApp) Timings() []HookTiming {
+	app.timingsMu.Lock()
+	defer app.timingsMu.Unlock()
+
+	return slices.Clone(app.timings)
+}
+
+func (app *BaseApp) recordTiming(t HookTiming) {
+	app.timingsMu.Lock()
+	app.timings = append(app.timings, t)
+	app.timingsMu.Unlock()
+
+	var limit time.Duration
+	switch t.Phase {
+	case PhaseStart:
+		limit = time.Duration(float64(app.startTimeout) * app.slowHookThreshold)
+	case PhaseStop:
+		limit = time.Duration(float64(app.stopTimeout) * app.slowHookThreshold)
+	}
+
+	if limit > 0 && t.Duration > limit {
+		_ = app.OnSlowHook().Trigger(&SlowHookEvent{App: app, Timing: t, Limit: limit}, app.warnSlowHook)
+	}
+}
+
+func (app *BaseApp) warnSlowHook(e *SlowHookEvent) error {
+	if app.fxLogger != nil {
+		app.fxLogger.LogEvent(&fxevent.Run{
+			Name:    e.Timing.Name,
+			Kind:    "slow hook",
+			Runtime: e.Timing.Duration,
+			Err:     fmt.Errorf("app: %s hook ran longer than %v", e.Timing.Phase, e.Limit),
+		})
+	}
+	return e.Next()
+}
+
+// recordHandler records a handler bound to the app's hook of phase and
+// reports it through the fx logger the way fx reports its hooks.
+func (app *BaseApp) recordHandler(phase, name string, d time.Duration, err error) {
+	t := HookTiming{Phase: phase, Name: name, Caller: app.name, Duration: d}
+	if err != nil {
+		t.Err = err.Error()
+	}
+	app.recordTiming(t)
+
+	if app.fxLogger == nil {
+		return
+	}
+	switch phase {
+	case PhaseBoot:
+		app.fxLogger.LogEvent(&fxevent.Run{Name: t.Name, Kind: phase, Runtime: d, Err: err})
+	case PhaseStart:
+		app.fxLogger.LogEvent(&fxevent.OnStartExecuted{FunctionName: t.Name, CallerName: t.Caller, Method: hookNames[phase], Runtime: d, Err: err})
+	case PhaseStop:
+		app.fxLogger.LogEvent(&fxevent.OnStopExecuted{FunctionName: t.Name, CallerName: t.Caller, Runtime: d, Err: err})
+	}
+}
+
+var hookNames = map[string]string{PhaseBoot: "OnBoot", PhaseStart: "OnStart", PhaseStop: "OnStop"}
+
+// timedEvent is an event whose Next reports to a handlerTimer.
+type timedEvent interface {
+	hook.Resolver
+	setTimer(t *handlerTimer)
+}
+
+// handlerTimer times the handlers bound to a hook one by one. The hook
+// enters every handler of the chain through the event's Next, so a handler
+// runs on its own from the Next call entering it until it calls Next itself,
+// which also tells its name, and again once that call returned.
+type handlerTimer struct {
+	app    *BaseApp
+	phase  string
+	bound  int
+	calls  int
+	frames []*handlerFrame
+}
+
+// handlerFrame is a handler of the chain that has not returned yet.
+type handlerFrame struct {
+	index   int
+	name    string
+	start   time.Time
+	nested  time.Duration
+	nextErr error
+}
+
+// next runs the rest of the chain on behalf of the handler calling the
+// event's Next, which called next through one method.
+func (t *handlerTimer) next(next func() error) error {
+	if t == nil {
+		return next()
+	}
+	if n := len(t.frames); n > 0 && t.frames[n-1].name == "" {
+		t.frames[n-1].name = callerName(2)
+	}
+
+	t.calls++
+	f := &handlerFrame{index: t.calls, start: time.Now()}
+	t.frames = append(t.frames, f)
+	err := next()
+	t.frames = t.frames[:len(t.frames)-1]
+
+	d := time.Since(f.start)
+	if n := len(t.frames); n > 0 {
+		t.frames[n-1].nested += d
+		t.frames[n-1].nextErr = err
+	}
+
+	// The calls after the bound handlers enter trigger's own handlers,
+	// whose fx work is timed by fx, or end the chain.
+	if f.index <= t.bound {
+		name := fmt.Sprintf("%s#%d", hookNames[t.phase], f.index)
+		if f.name != "" {
+			name += " " + f.name
+		}
+		ownErr := err
+		if err != nil && f.nextErr != nil && errors.Is(err, f.nextErr) {
+			ownErr = nil
+		}
+		t.app.recordHandler(t.phase, name, d-f.nested, ownErr)
+	}
+	return err
+}
+
+// callerName names the function skip frames above the caller of
+// callerName.
+func callerName(skip int) string {
+	pc := make([]uintptr, 1)
+	if runtime.Callers(skip+2, pc) == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames(pc).Next()
+	return frame.Function
+}
+
+// trigger runs a lifecycle hook chain, timing every bound handler. fns end
+// the chain. A panic in the chain is returned as a PanicError.
+func trigger[T timedEvent](app *BaseApp, phase string, h *hook.Hook[T], event T, fns ...func(T) error) error {
+	event.setTimer(&handlerTimer{app: app, phase: phase, bound: h.Length()})
+	defer event.setTimer(nil)
+
+	err := catchPanic(func() error { return h.Trigger(event, fns...) })
+
+	// fx gives up on a hook at the deadline without reporting it; record
+	// what was still running so the culprit shows up.
+	if errors.Is(err, context.DeadlineExceeded) {
+		now := time.Now()
+		for _, r := range app.hooks.snapshot() {
+			if r.phase == phase {
+				app.recordTiming(HookTiming{
+					Phase:    phase,
+					Name:     r.function,
+					Caller:   r.caller,
+					Duration: now.Sub(r.since),
+					Err:      "still running at timeout",
+				})
+			}
+		}
+	}
+
+	return err
+}
+
+// hookLogger passes fx events on while tracking running hooks for Dump and
+// recording hook timings.
+type hookLogger struct {
+	logger fxevent.Logger
+	app    *BaseApp
+}
+
+func (l *hookLogger) LogEvent(event fxevent.Event) {
+	// Wrapped hooks report themselves under their original names.
+	if wrapsHook(fxEventCaller(event)) {
+		return
+	}
+
+	hooks := &l.app.hooks
+
+	switch e := event.(type) {
+	case *fxevent.OnStartExecuting:
+		hooks.add(fxRunningHook(PhaseStart, e.FunctionName, e.CallerName))
+	case *fxevent.OnStartExecuted:
+		hooks.leave(fxHookName("OnStart", e.FunctionName, e.CallerName))
+		l.app.recordTiming(fxHookTiming(PhaseStart, e.FunctionName, e.CallerName, e.Runtime, e.Err))
+	case *fxevent.OnStopExecuting:
+		hooks.add(fxRunningHook(PhaseStop, e.FunctionName, e.CallerName))
+	case *fxevent.OnStopExecuted:
+		hooks.leave(fxHookName("OnStop", e.FunctionName, e.CallerName))
+		l.app.recordTiming(fxHookTiming(PhaseStop, e.FunctionName, e.CallerName, e.Runtime, e.Err))
+	case *fxevent.Run:
+		l.app.recordTiming(fxHookTiming(PhaseBoot, e.Name, e.ModuleName, e.Runtime, e.Err))
+	}
+
+	l.logger.LogEvent(event)
+}
+
+func fxEventCaller(event fxevent.Event) string {
+	switch e := event.(type) {
+	case *fxevent.OnStartExecuting:
+		return e.CallerName
+	case *fxevent.OnStartExecuted:
+		return e.CallerName
+	case *fxevent.OnStopExecuting:
+		return e.CallerName
+	case *fxevent.OnStopExecuted:
+		return e.CallerName
+	}
+	return ""
+}
+
+func fxHookName(method, function, caller string) string {
+	return fmt.Sprintf("fx %s hook %s (from %s)", method, function, caller)
+}
+
+func fxRunningHook(phase, function, caller string) runningHook {
+	method := "OnStart"
+	if phase == PhaseStop {
+		method = "OnStop"
+	}
+	return runningHook{name: fxHookName(method, function, caller), phase: phase, function: function, caller: caller}
+}
+
+func fxHookTiming(phase, name, caller string, d time.Duration, err error) HookTiming {
+	t := HookTiming{Phase: phase, Name: name, Caller: caller, Duration: d}
+	if err != nil {
+		t.Err = err.Error()
+	}
+	return t
+}
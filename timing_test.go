@@ -0,0 +1,165 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+
+	"github.com/rumorsflow/app"
+)
+
+func findTiming(timings []app.HookTiming, phase, name string) (app.HookTiming, bool) {
+	for _, t := range timings {
+		if t.Phase == phase && strings.Contains(t.Name, name) {
+			return t, true
+		}
+	}
+	return app.HookTiming{}, false
+}
+
+func newTimedDep() *depA {
+	return &depA{}
+}
+
+func TestTimings(t *testing.T) {
+	var log bytes.Buffer
+	a := newApp(t,
+		fx.Provide(newTimedDep),
+		fx.Invoke(func(lc fx.Lifecycle, _ *depA) {
+			lc.Append(fx.StartHook(func() { time.Sleep(10 * time.Millisecond) }))
+			lc.Append(fx.StopHook(func() {}))
+		}),
+	)
+	a.OnBoot().BindFunc(func(e *app.BootEvent) error {
+		e.Logger = &fxevent.ConsoleLogger{W: &log}
+		return e.Next()
+	})
+	a.OnStart().BindFunc(func(e *app.StartEvent) error {
+		return e.Next()
+	})
+	a.OnStart().BindFunc(func(e *app.StartEvent) error {
+		time.Sleep(20 * time.Millisecond)
+		return e.Next()
+	})
+
+	ctx := context.Background()
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if err := a.Stop(ctx); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+
+	timings := a.Timings()
+	for _, want := range []struct{ phase, name string }{
+		{app.PhaseBoot, "newTimedDep"},
+		{app.PhaseBoot, "OnBoot#1 github.com/rumorsflow/app.Options"},
+		{app.PhaseBoot, "OnBoot#2 github.com/rumorsflow/app_test.TestTimings"},
+		{app.PhaseStart, "func"},
+		{app.PhaseStart, "OnStart#1 github.com/rumorsflow/app_test.TestTimings"},
+		{app.PhaseStart, "OnStart#2 github.com/rumorsflow/app_test.TestTimings"},
+		{app.PhaseStop, "func"},
+	} {
+		if _, ok := findTiming(timings, want.phase, want.name); !ok {
+			t.Errorf("Timings() = %+v, missing %s %s", timings, want.phase, want.name)
+		}
+	}
+
+	quick, _ := findTiming(timings, app.PhaseStart, "OnStart#1")
+	slow, _ := findTiming(timings, app.PhaseStart, "OnStart#2")
+	if quick.Duration >= 20*time.Millisecond || slow.Duration < 20*time.Millisecond {
+		t.Errorf("OnStart handlers took %v and %v, want only the second one to take 20ms", quick.Duration, slow.Duration)
+	}
+
+	if !strings.Contains(log.String(), "OnStart#2 github.com/rumorsflow/app_test.TestTimings") {
+		t.Errorf("fx log = %s, want OnStart handler timings", log.String())
+	}
+	if strings.Contains(log.String(), "recoveringLifecycle") {
+		t.Errorf("fx log = %s, want no events of the panic wrappers", log.String())
+	}
+}
+
+func TestSlowHook(t *testing.T) {
+	var log bytes.Buffer
+	a := app.NewBaseApp(app.Config{
+		StartTimeout:      time.Second,
+		StopTimeout:       time.Second,
+		SlowHookThreshold: 0.02,
+	})
+	a.OnBoot().BindFunc(func(e *app.BootEvent) error {
+		e.Logger = &fxevent.ConsoleLogger{W: &log}
+		return e.Next()
+	})
+	a.OnBoot().BindFunc(app.Options(fx.Invoke(func(lc fx.Lifecycle) {
+		lc.Append(fx.StartHook(func() { time.Sleep(40 * time.Millisecond) }))
+	})))
+
+	var mu sync.Mutex
+	var slow []*app.SlowHookEvent
+	a.OnSlowHook().BindFunc(func(e *app.SlowHookEvent) error {
+		mu.Lock()
+		slow = append(slow, e)
+		mu.Unlock()
+		return e.Next()
+	})
+
+	ctx := context.Background()
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	t.Cleanup(func() { _ = a.Stop(context.Background()) })
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(slow) != 1 {
+		t.Fatalf("OnSlowHook triggered %d times, want 1", len(slow))
+	}
+	if e := slow[0]; e.Timing.Phase != app.PhaseStart || e.Limit != 20*time.Millisecond || e.Timing.Duration < e.Limit {
+		t.Errorf("SlowHookEvent = %+v", e)
+	}
+	if !strings.Contains(log.String(), "slow hook") {
+		t.Errorf("fx log = %s, want a slow hook warning", log.String())
+	}
+}
+
+func TestTimingsStuckStopHook(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	a := app.NewBaseApp(app.Config{
+		StartTimeout: time.Second,
+		StopTimeout:  50 * time.Millisecond,
+	})
+	a.OnBoot().BindFunc(app.Options(fx.Invoke(func(lc fx.Lifecycle) {
+		lc.Append(fx.StopHook(func() { <-release }))
+	})))
+
+	ctx := context.Background()
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() = %v", err)
+	}
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if err := a.Stop(ctx); err == nil {
+		t.Fatal("Stop() = nil, want timeout")
+	}
+
+	stuck, ok := findTiming(a.Timings(), app.PhaseStop, "func")
+	if !ok || stuck.Err != "still running at timeout" || stuck.Caller == "" {
+		t.Errorf("Timings() = %+v, want the stuck stop hook", a.Timings())
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/dig"
+	"go.uber.org/fx/fxevent"
+)
+
+// GraphError reports an fx dependency graph that cannot be built. Err is the
+// error returned by fx; Cause is its root cause as dig reports it, e.g. the
+// missing types or the error of a failing constructor.
+//
+// GraphError has no list of the missing types or of the types along a
+// cycle: dig keeps both in unexported error types and exposes them only in
+// Cause's message, which names each type as it appears in the constructor
+// signatures. Show Cause to humans, test Cycle to tell a cycle from a
+// missing type, and match the error of a failing constructor with errors.Is
+// or errors.As, which see through GraphError.
+type GraphError struct {
+	Cause error
+	// Cycle reports whether the graph has a dependency cycle.
+	Cycle bool
+	Err   error
+}
+
+func newGraphError(err error) *GraphError {
+	return &GraphError{Cause: dig.RootCause(err), Cycle: dig.IsCycleDetected(err), Err: err}
+}
+
+func (e *GraphError) Error() string {
+	return fmt.Sprintf("app: invalid dependency graph: %v", e.Err)
+}
+
+func (e *GraphError) Unwrap() error {
+	return e.Err
+}
+
+// Verifier is implemented by apps that can build their fx container without
+// running it, like BaseApp. The check command of Main uses it.
+type Verifier interface {
+	Verify(ctx context.Context) error
+}
+
+var _ Verifier = (*BaseApp)(nil)
+
+// Verify runs the OnBoot chain and builds the fx container, running its
+// constructors and invokes, without keeping it and without starting
+// anything. Graph failures are returned as *GraphError. The container gets
+// its own Listeners, Health, Shutdown, Workers and Scheduler, discarded
+// afterwards, so the app can still be booted.
+func (app *BaseApp) Verify(ctx context.Context) error {
+	event := &BootEvent{App: app, Ctx: ctx, Logger: fxevent.NopLogger}
+
+	return app.OnBoot().Trigger(event, app.verify)
+}
+
+func (app *BaseApp) verify(event *BootEvent) error {
+	if event.Logger == nil {
+		return errors.New("app: bootstrap fx event logger is nil")
+	}
+
+	reg := app.dryRunRegistries()
+	defer func() { _ = reg.close() }()
+
+	logger := event.Logger
+	if _, err := app.newFxApp(event, reg, func() fxevent.Logger { return logger }); err != nil {
+		return err
+	}
+
+	return event.Next()
+}
@@ -0,0 +1,125 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/fx"
+
+	"github.com/rumorsflow/app"
+)
+
+type (
+	depA struct{}
+	depB struct{}
+)
+
+func TestVerifyMissingDependency(t *testing.T) {
+	a := newApp(t, fx.Invoke(func(*depA) {}))
+
+	err := a.Verify(context.Background())
+
+	var graphErr *app.GraphError
+	if !errors.As(err, &graphErr) {
+		t.Fatalf("Verify() = %v, want *GraphError", err)
+	}
+	if graphErr.Cycle || graphErr.Cause == nil || !strings.Contains(graphErr.Cause.Error(), "*app_test.depA") {
+		t.Errorf("GraphError = %+v, want the missing *app_test.depA as cause", graphErr)
+	}
+}
+
+func TestVerifyCycle(t *testing.T) {
+	a := newApp(t,
+		fx.Provide(func(*depB) *depA { return nil }),
+		fx.Provide(func(*depA) *depB { return nil }),
+		fx.Invoke(func(*depA) {}),
+	)
+
+	err := a.Verify(context.Background())
+
+	var graphErr *app.GraphError
+	if !errors.As(err, &graphErr) {
+		t.Fatalf("Verify() = %v, want *GraphError", err)
+	}
+	if !graphErr.Cycle {
+		t.Errorf("GraphError = %+v, want a cycle", graphErr)
+	}
+}
+
+func TestVerifyInvokeError(t *testing.T) {
+	a := newApp(t, fx.Invoke(func() error { return errSentinel }))
+
+	if err := a.Verify(context.Background()); !errors.Is(err, errSentinel) {
+		t.Errorf("Verify() = %v, want %v", err, errSentinel)
+	}
+}
+
+func TestVerifyDoesNotBootOrStart(t *testing.T) {
+	var started bool
+	a := newApp(t, fx.Invoke(func(lc fx.Lifecycle) {
+		lc.Append(fx.StartHook(func() { started = true }))
+	}))
+
+	ctx := context.Background()
+	if err := a.Verify(ctx); err != nil {
+		t.Fatalf("Verify() = %v", err)
+	}
+	if started {
+		t.Error("Verify started the app")
+	}
+	if err := a.Start(ctx); err == nil || !strings.Contains(err.Error(), "not booted") {
+		t.Errorf("Start() after Verify = %v, want not-booted error", err)
+	}
+
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() after Verify = %v", err)
+	}
+}
+
+// registeringInvoke registers a health check and a worker, which a dry run
+// must not leave behind for Boot.
+func registeringInvoke(runs *atomic.Int32) fx.Option {
+	return fx.Invoke(func(h *app.Health, w *app.Workers) error {
+		return errors.Join(
+			h.Register(app.Check{Name: "db", Check: func(context.Context) error { return nil }}),
+			w.Go(app.Worker{Name: "counter", Run: func(context.Context) error {
+				runs.Add(1)
+				return nil
+			}}),
+		)
+	})
+}
+
+func TestVerifyThenBoot(t *testing.T) {
+	var runs atomic.Int32
+	a := newApp(t, registeringInvoke(&runs))
+
+	ctx := context.Background()
+	if err := a.Verify(ctx); err != nil {
+		t.Fatalf("Verify() = %v", err)
+	}
+	if err := a.Boot(ctx); err != nil {
+		t.Fatalf("Boot() after Verify = %v", err)
+	}
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if err := a.Stop(ctx); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if n := runs.Load(); n != 1 {
+		t.Errorf("worker ran %d times, want once", n)
+	}
+}
+
+func TestBootReportsGraphError(t *testing.T) {
+	a := newApp(t, fx.Invoke(func(*depA) {}))
+
+	var graphErr *app.GraphError
+	if err := a.Boot(context.Background()); !errors.As(err, &graphErr) {
+		t.Errorf("Boot() = %v, want *GraphError", err)
+	}
+}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RestartPolicy decides whether a worker runs again after it returned.
+type RestartPolicy int
+
+const (
+	// RestartNever runs the worker once.
+	RestartNever RestartPolicy = iota
+	// RestartOnFailure runs it again after it returned an error.
+	RestartOnFailure
+	// RestartAlways runs it again whenever it returned before Stop.
+	RestartAlways
+)
+
+const (
+	defaultWorkerBackoff    = time.Second
+	defaultWorkerMaxBackoff = time.Minute
+)
+
+// Worker is a long-running loop supervised by Workers.
+type Worker struct {
+	Name string
+	Run  func(ctx context.Context) error
+	// Restart is RestartNever when zero.
+	Restart RestartPolicy
+	// Backoff is the delay before the first restart, doubled for every
+	// further one up to MaxBackoff; 1s and 1m when zero. A run outlasting
+	// MaxBackoff resets it.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// MaxRestarts caps the restarts; unlimited when zero.
+	MaxRestarts int
+	// Fatal stops the app once the worker fails for good, and Run returns
+	// its error.
+	Fatal bool
+}
+
+// WorkerError is the final error of a worker that gave up.
+type WorkerError struct {
+	Worker   string
+	Restarts int
+	Err      error
+}
+
+func (e *WorkerError) Error() string {
+	return fmt.Sprintf("app: worker %s failed after %d restarts: %v", e.Worker, e.Restarts, e.Err)
+}
+
+func (e *WorkerError) Unwrap() error {
+	return e.Err
+}
+
+// Workers supervises background loops for the app's lifetime. Workers start
+// once the app runs, or right away when added later, and their contexts are
+// cancelled in the StopWorkers phase of Shutdown and awaited within its
+// budget. It is supplied to the fx graph by Boot:
+//
+//	fx.Invoke(func(w *app.Workers, c *Consumer) error {
+//		return w.Go(app.Worker{Name: "consumer", Run: c.Run, Restart: app.RestartOnFailure})
+//	})
+type Workers struct {
+	mu      sync.Mutex
+	pending []Worker
+	ctx     context.Context
+	cancel  context.CancelFunc
+	stopped bool
+	wg      sync.WaitGroup
+	fail    func(error)
+}
+
+func newWorkers(fail func(error)) *Workers {
+	return &Workers{fail: fail}
+}
+
+// Go adds a worker.
+func (w *Workers) Go(worker Worker) error {
+	if worker.Name == "" || worker.Run == nil {
+		return errors.New("app: worker needs a name and a func")
+	}
+	if worker.Backoff <= 0 {
+		worker.Backoff = defaultWorkerBackoff
+	}
+	if worker.MaxBackoff <= 0 {
+		worker.MaxBackoff = defaultWorkerMaxBackoff
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch {
+	case w.stopped:
+		return fmt.Errorf("app: cannot add worker %s: workers stopped", worker.Name)
+	case w.ctx == nil:
+		w.pending = append(w.pending, worker)
+	default:
+		w.launch(worker)
+	}
+
+	return nil
+}
+
+func (w *Workers) start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.ctx != nil || w.stopped {
+		return
+	}
+
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	for _, worker := range w.pending {
+		w.launch(worker)
+	}
+	w.pending = nil
+}
+
+func (w *Workers) launch(worker Worker) {
+	ctx := w.ctx
+	w.wg.Go(func() {
+		if err := supervise(ctx, worker); err != nil && worker.Fatal && ctx.Err() == nil {
+			w.fail(err)
+		}
+	})
+}
+
+// stop cancels the workers and waits for them until ctx is done. Calls after
+// the first only wait.
+func (w *Workers) stop(ctx context.Context) error {
+	w.mu.Lock()
+	w.stopped = true
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("app: workers did not stop: %w", ctx.Err())
+	}
+}
+
+// supervise runs worker according to its restart policy until ctx is done,
+// and returns the error it gave up with. A panic counts as a failure with a
+// PanicError.
+func supervise(ctx context.Context, worker Worker) error {
+	backoff := worker.Backoff
+
+	for restarts := 0; ; restarts++ {
+		start := time.Now()
+		err := catchPanic(func() error { return worker.Run(ctx) })
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		switch {
+		case worker.Restart == RestartNever,
+			worker.Restart == RestartOnFailure && err == nil:
+			return wrapWorkerError(worker.Name, restarts, err)
+		case worker.MaxRestarts > 0 && restarts >= worker.MaxRestarts:
+			if err == nil {
+				err = errors.New("returned too often")
+			}
+			return wrapWorkerError(worker.Name, restarts, err)
+		}
+
+		if time.Since(start) > worker.MaxBackoff {
+			backoff = worker.Backoff
+		}
+
+		t := time.NewTimer(backoff)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil
+		}
+
+		backoff = min(backoff*2, worker.MaxBackoff)
+	}
+}
+
+func wrapWorkerError(name string, restarts int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &WorkerError{Worker: name, Restarts: restarts, Err: err}
+}
+
+func (app *BaseApp) Workers() *Workers {
+	return app.workers
+}
+
+// fail stops the app because of err, which Run and Stop then return.
+func (app *BaseApp) fail(err error) {
+	app.stateMu.Lock()
+	app.failErr = errors.Join(app.failErr, err)
+	app.stateMu.Unlock()
+
+	go func() { _ = app.Stop(context.Background()) }()
+}
@@ -0,0 +1,143 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/rumorsflow/app"
+)
+
+func workerApp(t *testing.T, workers ...app.Worker) *app.BaseApp {
+	t.Helper()
+
+	return newStartedApp(t, fx.Invoke(func(w *app.Workers) error {
+		for _, worker := range workers {
+			if err := w.Go(worker); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func TestWorkersRestartOnFailure(t *testing.T) {
+	var runs atomic.Int32
+	gaveUp := make(chan struct{})
+
+	a := workerApp(t, app.Worker{
+		Name: "flaky",
+		Run: func(context.Context) error {
+			if runs.Add(1) == 3 {
+				close(gaveUp)
+			}
+			return errSentinel
+		},
+		Restart:     app.RestartOnFailure,
+		Backoff:     time.Millisecond,
+		MaxRestarts: 2,
+	})
+
+	waitClosed(t, gaveUp, "worker was not restarted")
+	time.Sleep(20 * time.Millisecond)
+
+	if n := runs.Load(); n != 3 {
+		t.Errorf("worker ran %d times, want 3", n)
+	}
+	if s := a.State(); s != app.StateRunning {
+		t.Errorf("State() = %v, want running after a non-fatal worker gave up", s)
+	}
+}
+
+func TestWorkersRestartAlways(t *testing.T) {
+	var runs atomic.Int32
+	restarted := make(chan struct{})
+
+	workerApp(t, app.Worker{
+		Name: "ticker",
+		Run: func(context.Context) error {
+			if runs.Add(1) == 3 {
+				close(restarted)
+			}
+			return nil
+		},
+		Restart: app.RestartAlways,
+		Backoff: time.Millisecond,
+	})
+
+	waitClosed(t, restarted, "worker returning nil was not restarted")
+}
+
+func TestWorkersStopCancelsAndWaits(t *testing.T) {
+	started := make(chan struct{})
+	var finished atomic.Bool
+
+	a := workerApp(t, app.Worker{
+		Name: "loop",
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			time.Sleep(20 * time.Millisecond)
+			finished.Store(true)
+			return ctx.Err()
+		},
+		Restart: app.RestartAlways,
+		Fatal:   true,
+	})
+
+	waitClosed(t, started, "worker did not start")
+
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Stop() returned before the worker finished")
+	}
+	if err := a.Workers().Go(app.Worker{Name: "late", Run: waitDone}); err == nil {
+		t.Error("Go() after Stop = nil, want error")
+	}
+}
+
+func TestWorkersFatalStopsRun(t *testing.T) {
+	a := newApp(t, fx.Invoke(func(w *app.Workers) error {
+		return w.Go(app.Worker{
+			Name:  "fatal",
+			Run:   func(context.Context) error { return errSentinel },
+			Fatal: true,
+		})
+	}))
+
+	runErr, started := startRun(t, a)
+	waitClosed(t, started, "app did not start")
+
+	err := waitErr(t, runErr)
+	var workerErr *app.WorkerError
+	if !errors.As(err, &workerErr) || workerErr.Worker != "fatal" || !errors.Is(err, errSentinel) {
+		t.Errorf("Run() = %v, want the fatal worker's error", err)
+	}
+}
+
+func TestWorkersPanicStopsRun(t *testing.T) {
+	a := newApp(t, fx.Invoke(func(w *app.Workers) error {
+		return w.Go(app.Worker{
+			Name:  "panicking",
+			Run:   func(context.Context) error { panic(errSentinel) },
+			Fatal: true,
+		})
+	}))
+
+	runErr, started := startRun(t, a)
+	waitClosed(t, started, "app did not start")
+
+	err := waitErr(t, runErr)
+	if !errors.As(err, new(*app.PanicError)) || !errors.Is(err, errSentinel) {
+		t.Errorf("Run() = %v, want the worker's panic", err)
+	}
+	if code := app.ExitCode(err); code != app.ExitSoftware {
+		t.Errorf("ExitCode() = %d, want %d", code, app.ExitSoftware)
+	}
+}